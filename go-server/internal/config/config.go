@@ -3,22 +3,41 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
+	Server            ServerConfig
+	Database          DatabaseConfig
+	JWT               JWTConfig
+	CORS              CORSConfig
+	Metadata          MetadataConfig
+	Search            SearchConfig
+	Share             ShareConfig
+	Google            GoogleConfig
+	OAuth             OAuthConfig
+	Password          PasswordConfig
+	Login             LoginConfig
+	RateLimit         RateLimitConfig
+	Redis             RedisConfig
+	Mail              MailConfig
+	EmailVerification EmailVerificationConfig
+	MagicLink         MagicLinkConfig
+	MFA               MFAConfig
+	WebAuthn          WebAuthnConfig
 }
 
 type ServerConfig struct {
 	Port      int
 	Env       string
 	AssetsURL string
+	// TrustedProxies are the addresses or CIDRs of reverse proxies whose
+	// X-Forwarded-For headers are believed. Other peers' headers are
+	// ignored, so clients cannot pick their own IP.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -37,10 +56,131 @@ type JWTConfig struct {
 }
 
 type CORSConfig struct {
-	Origin     string
+	Origin      string
 	Credentials bool
 }
 
+type MetadataConfig struct {
+	FetchTimeout time.Duration
+	MaxBodyBytes int64
+}
+
+type SearchConfig struct {
+	// FuzzyThreshold is the minimum pg_trgm word similarity (0-1) for a
+	// typo-tolerant match.
+	FuzzyThreshold float64
+}
+
+type ShareConfig struct {
+	// BaseURL is prefixed to share tokens to build public collection links.
+	BaseURL string
+}
+
+type GoogleConfig struct {
+	// ClientIDs are the OAuth client IDs (web, iOS, Android) whose ID
+	// tokens are accepted. Google sign-in is off when empty.
+	ClientIDs []string
+}
+
+type OAuthConfig struct {
+	// CallbackBaseURL is where providers send users back to; the provider
+	// name and "/callback" are appended.
+	CallbackBaseURL string
+	// RedirectURL is the app page users land on after a browser sign-in.
+	// Callbacks answer with JSON when empty.
+	RedirectURL string
+
+	GoogleClientSecret string
+	GitHubClientID     string
+	GitHubClientSecret string
+	AppleClientID      string
+	AppleTeamID        string
+	AppleKeyID         string
+	ApplePrivateKey    string
+}
+
+type PasswordConfig struct {
+	// ResetURL is the app screen password reset links open; the token is
+	// added as the "token" query parameter.
+	ResetURL string
+}
+
+type LoginConfig struct {
+	// Store keeps failed sign-ins: "postgres", shared by every instance,
+	// or "memory".
+	Store string
+	// FailureWindow is how long a failed sign-in counts.
+	FailureWindow time.Duration
+	// AccountLockoutAfter failures within FailureWindow lock an account
+	// out for LockoutDuration; IPLockoutAfter does the same for a client
+	// IP.
+	AccountLockoutAfter int
+	IPLockoutAfter      int
+	LockoutDuration     time.Duration
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Store keeps the token buckets: "memory", per instance, or "redis",
+	// shared by every instance.
+	Store string
+	// Rules are the limits per route group, such as
+	// "auth=ip:30/1m;links=user:120/1m"; see ratelimit.ParsePolicy.
+	Rules string
+}
+
+type RedisConfig struct {
+	// URL is a redis:// or rediss:// URL.
+	URL string
+}
+
+type EmailVerificationConfig struct {
+	// URL is the app screen verification links open; the token is added
+	// as the "token" query parameter.
+	URL string
+}
+
+type MagicLinkConfig struct {
+	// URL is the app screen sign-in links open; the token is added as the
+	// "token" query parameter.
+	URL string
+	// AutoRegister creates an account for an unknown address when its
+	// link is used. Unknown addresses get no email when false.
+	AutoRegister bool
+}
+
+type MFAConfig struct {
+	// Issuer names the app in authenticator apps.
+	Issuer string
+	// EncryptionKey encrypts TOTP secrets at rest: 32 bytes, base64. A key
+	// derived from JWT_SECRET is used when empty.
+	EncryptionKey string
+}
+
+type WebAuthnConfig struct {
+	// RPID is the domain passkeys are bound to: the host of the origins
+	// or a parent domain of it.
+	RPID   string
+	RPName string
+	// Origins are the web origins, and android:apk-key-hash: origins of
+	// the app, passkey ceremonies may come from. Passkeys are off when
+	// empty.
+	Origins []string
+}
+
+type MailConfig struct {
+	// Mode is "log" (print mail to the server log), "outbox" (write .eml
+	// files to OutboxDir) or "smtp".
+	Mode          string
+	From          string
+	DefaultLocale string
+	OutboxDir     string
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+}
+
 var AppConfig *Config
 
 func Load() error {
@@ -48,9 +188,10 @@ func Load() error {
 
 	AppConfig = &Config{
 		Server: ServerConfig{
-			Port:      getEnvAsInt("SERVER_PORT", 8000),
-			Env:       getEnv("NODE_ENV", "development"),
-			AssetsURL: getEnv("ASSETS_URL", "http://localhost:8000"),
+			Port:           getEnvAsInt("SERVER_PORT", 8000),
+			Env:            getEnv("NODE_ENV", "development"),
+			AssetsURL:      getEnv("ASSETS_URL", "http://localhost:8000"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "postgres"),
@@ -69,6 +210,74 @@ func Load() error {
 			Origin:      getEnv("CORS_ORIGIN", "*"),
 			Credentials: true,
 		},
+		Metadata: MetadataConfig{
+			FetchTimeout: parseDuration(getEnv("METADATA_FETCH_TIMEOUT", "8s")),
+			MaxBodyBytes: int64(getEnvAsInt("METADATA_MAX_BODY_BYTES", 1<<20)),
+		},
+		Search: SearchConfig{
+			FuzzyThreshold: getEnvAsFloat("SEARCH_FUZZY_THRESHOLD", 0.4),
+		},
+		Share: ShareConfig{
+			BaseURL: getEnv("SHARE_BASE_URL", "http://localhost:8000/api/shared"),
+		},
+		Google: GoogleConfig{
+			ClientIDs: getEnvAsList("GOOGLE_CLIENT_ID"),
+		},
+		OAuth: OAuthConfig{
+			CallbackBaseURL:    getEnv("OAUTH_CALLBACK_BASE_URL", "http://localhost:8000/api/auth/oauth"),
+			RedirectURL:        getEnv("OAUTH_REDIRECT_URL", ""),
+			GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+			GitHubClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+			GitHubClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+			AppleClientID:      getEnv("APPLE_CLIENT_ID", ""),
+			AppleTeamID:        getEnv("APPLE_TEAM_ID", ""),
+			AppleKeyID:         getEnv("APPLE_KEY_ID", ""),
+			ApplePrivateKey:    getEnv("APPLE_PRIVATE_KEY", ""),
+		},
+		Password: PasswordConfig{
+			ResetURL: getEnv("PASSWORD_RESET_URL", "video-mobile-application://reset-password"),
+		},
+		Login: LoginConfig{
+			Store:               getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+			FailureWindow:       parseDuration(getEnv("LOGIN_FAILURE_WINDOW", "15m")),
+			AccountLockoutAfter: getEnvAsInt("LOGIN_ACCOUNT_LOCKOUT_AFTER", 10),
+			IPLockoutAfter:      getEnvAsInt("LOGIN_IP_LOCKOUT_AFTER", 100),
+			LockoutDuration:     parseDuration(getEnv("LOGIN_LOCKOUT_DURATION", "15m")),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Store:   getEnv("RATE_LIMIT_STORE", "memory"),
+			Rules:   getEnv("RATE_LIMITS", "auth=ip:60/1m;auth.email=ip:10/1h;links=user:120/1m;tags=user:300/1m;collections=user:300/1m;shared=ip:120/1m;admin=user:120/1m"),
+		},
+		Redis: RedisConfig{
+			URL: getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		},
+		EmailVerification: EmailVerificationConfig{
+			URL: getEnv("EMAIL_VERIFICATION_URL", "video-mobile-application://verify-email"),
+		},
+		MagicLink: MagicLinkConfig{
+			URL:          getEnv("MAGIC_LINK_URL", "video-mobile-application://magic-link"),
+			AutoRegister: getEnvAsBool("MAGIC_LINK_AUTO_REGISTER", false),
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "Video Vault"),
+			EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:  getEnv("WEBAUTHN_RP_NAME", "Video Vault"),
+			Origins: getEnvAsList("WEBAUTHN_ORIGINS"),
+		},
+		Mail: MailConfig{
+			Mode:          getEnv("MAIL_MODE", "log"),
+			From:          getEnv("MAIL_FROM", "Video Vault <no-reply@localhost>"),
+			DefaultLocale: getEnv("MAIL_DEFAULT_LOCALE", "en"),
+			OutboxDir:     getEnv("MAIL_OUTBOX_DIR", "tmp/outbox"),
+			SMTPHost:      getEnv("SMTP_HOST", ""),
+			SMTPPort:      getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+		},
 	}
 
 	return nil
@@ -89,6 +298,33 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func parseDuration(s string) time.Duration {
 	if len(s) < 2 {
 		return time.Hour
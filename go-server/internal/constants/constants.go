@@ -5,8 +5,20 @@ const (
 )
 
 const (
-	CookieAccessToken  = "access_token"
-	CookieRefreshToken = "refresh_token"
+	CookieAccessToken    = "access_token"
+	CookieRefreshToken   = "refresh_token"
+	CookieOAuthState     = "oauth_state"
+	CookieMagicLinkNonce = "magic_link_nonce"
+)
+
+const (
+	OAuthProviderGoogle = "google"
+	OAuthProviderGitHub = "github"
+	OAuthProviderApple  = "apple"
+)
+
+const (
+	HeaderSharePassword = "X-Share-Password"
 )
 
 const (
@@ -14,3 +26,30 @@ const (
 	RoleAdmin     = "admin"
 	RoleSuperAdmin = "super_admin"
 )
+
+const (
+	SourceInstagram = "instagram"
+	SourceFacebook  = "facebook"
+	SourceTwitter   = "twitter"
+	SourceTikTok    = "tiktok"
+	SourceYouTube   = "youtube"
+	SourceLinkedIn  = "linkedin"
+	SourceVimeo     = "vimeo"
+	SourceOther     = "other"
+)
+
+const (
+	CategoryNature        = "nature"
+	CategoryCooking       = "cooking"
+	CategoryFood          = "food"
+	CategorySports        = "sports"
+	CategoryMusic         = "music"
+	CategoryTech          = "tech"
+	CategoryEntertainment = "entertainment"
+	CategoryOther         = "other"
+)
+
+const (
+	MatchTypeFullText = "fulltext"
+	MatchTypeFuzzy    = "fuzzy"
+)
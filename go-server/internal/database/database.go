@@ -31,7 +31,8 @@ func Connect() error {
 	}
 
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger:         logger.Default.LogMode(logLevel),
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -44,13 +45,70 @@ func Connect() error {
 }
 
 func AutoMigrate() error {
+	// Canonical urls are backfilled once, when the unique index that keeps
+	// them consistent is first created.
+	canonicalIndexMissing := needsLinkCanonicalIndex()
+	if canonicalIndexMissing {
+		if err := prepareLinkCanonicalIndex(); err != nil {
+			return err
+		}
+	}
+
+	// Accounts that exist before email verification are verified once,
+	// when the column is created; later ones must prove their address.
+	emailVerifiedColumnMissing := needsEmailVerifiedColumn()
+
 	err := DB.AutoMigrate(
 		&models.User{},
+		&models.UserIdentity{},
+		&models.Session{},
+		&models.RevokedToken{},
+		&models.LoginFailure{},
+		&models.LoginLockout{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.MagicLinkToken{},
+		&models.MFARecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.Link{},
+		&models.Collection{},
+		&models.CollectionItem{},
+		&models.CollectionShare{},
+		&models.Tag{},
+		&models.LinkTag{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate: %w", err)
 	}
 
+	if err := backfillUserIdentities(); err != nil {
+		return err
+	}
+
+	if err := backfillEmailVerified(); err != nil {
+		return err
+	}
+
+	if emailVerifiedColumnMissing {
+		if err := backfillLegacyPasswordsVerified(); err != nil {
+			return err
+		}
+	}
+
+	if canonicalIndexMissing {
+		if err := backfillLinkCanonicalURLs(); err != nil {
+			return err
+		}
+	}
+
+	if err := migrateLinkSearch(); err != nil {
+		return err
+	}
+
+	if err := migrateLinkFuzzySearch(); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
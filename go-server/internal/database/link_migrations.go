@@ -0,0 +1,189 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/platform"
+)
+
+// linkCanonicalIndex is the unique (user_id, canonical_url) index. Once it
+// exists, every link saved since carries its canonical URL, so the
+// one-off steps below only run while it is missing.
+const linkCanonicalIndex = "uniq_links_user_canonical_url"
+
+// canonicalBackfillBatch bounds the rows updated per statement, well under
+// Postgres's limit on bind parameters.
+const canonicalBackfillBatch = 1000
+
+// needsLinkCanonicalIndex reports whether the unique canonical url index
+// has yet to be created.
+func needsLinkCanonicalIndex() bool {
+	return !DB.Migrator().HasIndex(&models.Link{}, linkCanonicalIndex)
+}
+
+// prepareLinkCanonicalIndex readies existing data for the unique
+// (user_id, canonical_url) index: it drops the earlier non-unique index and
+// clears canonical_url on every copy of a duplicate except the oldest, so
+// AutoMigrate can create the unique index.
+func prepareLinkCanonicalIndex() error {
+	if !DB.Migrator().HasColumn(&models.Link{}, "canonical_url") {
+		return nil
+	}
+
+	if err := DB.Exec(`DROP INDEX IF EXISTS idx_links_user_canonical_url`).Error; err != nil {
+		return fmt.Errorf("failed to drop old canonical url index: %w", err)
+	}
+
+	result := DB.Exec(`
+		UPDATE links SET canonical_url = NULL
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY user_id, canonical_url ORDER BY created_at, id
+				) AS rn
+				FROM links
+				WHERE canonical_url IS NOT NULL
+			) ranked
+			WHERE rn > 1
+		)`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear duplicate canonical urls: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Cleared canonical url on %d duplicate links", result.RowsAffected)
+	}
+
+	return nil
+}
+
+type canonicalURLUpdate struct {
+	ID           uuid.UUID
+	CanonicalURL string
+}
+
+// backfillLinkCanonicalURLs sets canonical_url on links saved before it
+// existed. A link whose canonical URL is already taken by another of the
+// user's links is a duplicate and is left without one.
+func backfillLinkCanonicalURLs() error {
+	var links []struct {
+		ID     uuid.UUID
+		UserID uuid.UUID
+		URL    string
+	}
+	err := DB.Model(&models.Link{}).
+		Select("id, user_id, url").
+		Where("canonical_url IS NULL").
+		Order("created_at, id").
+		Find(&links).Error
+	if err != nil {
+		return fmt.Errorf("failed to load links for canonical url backfill: %w", err)
+	}
+
+	// Links come oldest first, so the oldest copy of a duplicate keeps the
+	// canonical URL, matching prepareLinkCanonicalIndex.
+	seen := make(map[[2]string]bool)
+	updates := make([]canonicalURLUpdate, 0, len(links))
+	for _, link := range links {
+		match, err := platform.Resolve(link.URL)
+		if err != nil {
+			continue
+		}
+		key := [2]string{link.UserID.String(), match.CanonicalURL}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		updates = append(updates, canonicalURLUpdate{ID: link.ID, CanonicalURL: match.CanonicalURL})
+	}
+
+	var updated int64
+	for len(updates) > 0 {
+		batch := updates
+		if len(batch) > canonicalBackfillBatch {
+			batch = batch[:canonicalBackfillBatch]
+		}
+		updates = updates[len(batch):]
+
+		rows, err := setCanonicalURLs(batch)
+		if err != nil {
+			return err
+		}
+		updated += rows
+	}
+
+	if updated > 0 {
+		log.Printf("Backfilled canonical url on %d links", updated)
+	}
+	return nil
+}
+
+// setCanonicalURLs applies a batch of canonical URLs in one statement,
+// skipping any the user's other links already hold.
+func setCanonicalURLs(batch []canonicalURLUpdate) (int64, error) {
+	values := make([]string, 0, len(batch))
+	args := make([]interface{}, 0, 2*len(batch))
+	for _, update := range batch {
+		values = append(values, "(?::uuid, ?)")
+		args = append(args, update.ID, update.CanonicalURL)
+	}
+
+	result := DB.Exec(`
+		UPDATE links SET canonical_url = v.canonical_url
+		FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, canonical_url)
+		WHERE links.id = v.id AND NOT EXISTS (
+			SELECT 1 FROM links other
+			WHERE other.user_id = links.user_id AND other.canonical_url = v.canonical_url
+		)`, args...)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to backfill canonical urls: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// migrateLinkSearch adds the full-text search column and its GIN index.
+// Title weighs most, then the creator, then description and notes. The
+// column is generated, so Postgres keeps it in sync on every write.
+func migrateLinkSearch() error {
+	err := DB.Exec(`
+		ALTER TABLE links ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(author_name, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'C') ||
+			setweight(to_tsvector('english', coalesce(notes, '')), 'C')
+		) STORED`).Error
+	if err != nil {
+		return fmt.Errorf("failed to add links search vector: %w", err)
+	}
+
+	err = DB.Exec(`CREATE INDEX IF NOT EXISTS idx_links_search_vector ON links USING GIN (search_vector)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create links search index: %w", err)
+	}
+
+	return nil
+}
+
+// migrateLinkFuzzySearch enables pg_trgm and indexes the columns fuzzy
+// search compares against.
+func migrateLinkFuzzySearch() error {
+	if err := DB.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		return fmt.Errorf("failed to enable pg_trgm: %w", err)
+	}
+
+	for _, column := range []string{"title", "author_name"} {
+		err := DB.Exec(fmt.Sprintf(
+			`CREATE INDEX IF NOT EXISTS idx_links_%s_trgm ON links USING GIN (%s gin_trgm_ops)`,
+			column, column,
+		)).Error
+		if err != nil {
+			return fmt.Errorf("failed to create links %s trigram index: %w", column, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/video-mobile-app/go-server/internal/models"
+)
+
+// backfillUserIdentities copies accounts linked through the users
+// oauth_provider/oauth_id columns into user_identities, which is where
+// sign-in looks them up.
+func backfillUserIdentities() error {
+	result := DB.Exec(`
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at, last_login_at)
+		SELECT gen_random_uuid(), id, oauth_provider, oauth_id, email, NOW(), NOW()
+		FROM users
+		WHERE oauth_provider IS NOT NULL AND oauth_id IS NOT NULL
+		ON CONFLICT (provider, subject) DO NOTHING`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill user identities: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled %d user identities", result.RowsAffected)
+	}
+	return nil
+}
+
+// backfillEmailVerified marks accounts as verified when a linked sign-in
+// provider has already vouched for their email address. Sign-in only links
+// providers through verified addresses.
+func backfillEmailVerified() error {
+	result := DB.Exec(`
+		UPDATE users SET email_verified_at = users.created_at
+		WHERE users.email_verified_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM user_identities
+			WHERE user_identities.user_id = users.id
+			AND LOWER(user_identities.email) = users.email
+		)`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill verified emails: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Marked %d provider-linked accounts as email verified", result.RowsAffected)
+	}
+	return nil
+}
+
+// needsEmailVerifiedColumn reports whether users has yet to get the
+// email_verified_at column, i.e. whether every existing account predates
+// email verification.
+func needsEmailVerifiedColumn() bool {
+	return !DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+}
+
+// backfillLegacyPasswordsVerified marks password accounts as verified. It
+// runs once, right after the email_verified_at column is created, when
+// every account predates email verification: they were never asked to
+// verify, and treating them as unverified would let the first sign-in by
+// link or provider drop their password as if someone else had set it.
+func backfillLegacyPasswordsVerified() error {
+	result := DB.Exec(`
+		UPDATE users SET email_verified_at = users.created_at
+		WHERE users.email_verified_at IS NULL
+		AND users.password IS NOT NULL`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill verified emails of legacy accounts: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Marked %d accounts created before email verification as verified", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "github.com/video-mobile-app/go-server/internal/rbac"
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ValidateRole accepts the roles in the rbac permission matrix.
+func (r *UpdateUserRoleRequest) ValidateRole() error {
+	if !rbac.IsRole(r.Role) {
+		return &ValidationError{Field: "role", Message: "Role must be one of user, admin or super_admin"}
+	}
+	return nil
+}
+
+type UserDetailResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Data    *UserResponse `json:"data,omitempty"`
+}
@@ -9,8 +9,12 @@ type RegisterRequest struct {
 }
 
 func (r *RegisterRequest) ValidatePassword() error {
-	password := r.Password
+	return validatePassword(r.Password)
+}
 
+// validatePassword applies the password policy beyond the length limits
+// checked by binding.
+func validatePassword(password string) error {
 	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
 	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
 	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
@@ -47,25 +51,89 @@ type LoginRequest struct {
 }
 
 type UserResponse struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Email     string  `json:"email"`
-	Avatar    *string `json:"avatar,omitempty"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	Avatar        *string `json:"avatar,omitempty"`
+	Role          string  `json:"role"`
+	EmailVerified bool    `json:"email_verified"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
 }
 
 type AuthResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    *AuthData   `json:"data,omitempty"`
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	Data    *AuthData `json:"data,omitempty"`
+	// MFARequired is set instead of Data when the password was right but
+	// the user must still finish sign-in at /api/auth/mfa/verify.
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
 type AuthData struct {
 	User UserResponse `json:"user"`
 }
 
+// GoogleAuthRequest carries the ID token from Google Sign-In. The field is
+// camelCase to match what the mobile app already sends.
+type GoogleAuthRequest struct {
+	IDToken string `json:"idToken" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type TokenResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required,max=256"`
+	Password string `json:"password" binding:"required,min=8,max=128"`
+}
+
+// ValidatePassword applies the same rules as RegisterRequest.
+func (r *ResetPasswordRequest) ValidatePassword() error {
+	return validatePassword(r.Password)
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required,max=256"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+type MagicLinkConsumeRequest struct {
+	Token string `json:"token" binding:"required,max=1024"`
+}
+
+// UpdateProfileRequest changes only the fields that are present. An empty
+// avatar removes it.
+type UpdateProfileRequest struct {
+	Name   *string `json:"name" binding:"omitempty,max=100"`
+	Avatar *string `json:"avatar" binding:"omitempty,max=500"`
+}
+
+// ChangePasswordRequest uses camelCase to match what the mobile app
+// already sends.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required,max=128"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8,max=128"`
+}
+
+// ValidatePassword applies the same rules as RegisterRequest.
+func (r *ChangePasswordRequest) ValidatePassword() error {
+	if err := validatePassword(r.NewPassword); err != nil {
+		return &ValidationError{Field: "newPassword", Message: err.Error()}
+	}
+	return nil
+}
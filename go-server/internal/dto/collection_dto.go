@@ -0,0 +1,62 @@
+package dto
+
+type CreateCollectionRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
+
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
+
+type AddCollectionItemRequest struct {
+	LinkID string `json:"link_id" binding:"required,uuid"`
+}
+
+// MoveCollectionItemRequest places an item directly after AfterLinkID, or
+// first in the collection when AfterLinkID is omitted or null.
+type MoveCollectionItemRequest struct {
+	AfterLinkID *string `json:"after_link_id" binding:"omitempty,uuid"`
+}
+
+type CollectionResponse struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	ItemCount   int64   `json:"item_count"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+type CollectionItemResponse struct {
+	LinkResponse
+	Position int64  `json:"position"`
+	AddedAt  string `json:"added_at"`
+}
+
+type CollectionDetailResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Data    *CollectionResponse `json:"data,omitempty"`
+}
+
+type CollectionListResponse struct {
+	Success    bool                 `json:"success"`
+	Message    string               `json:"message"`
+	Data       []CollectionResponse `json:"data"`
+	Pagination *Pagination          `json:"pagination"`
+}
+
+type CollectionItemListResponse struct {
+	Success    bool                     `json:"success"`
+	Message    string                   `json:"message"`
+	Data       []CollectionItemResponse `json:"data"`
+	Pagination *Pagination              `json:"pagination"`
+}
+
+type CollectionItemDetailResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	Data    *CollectionItemResponse `json:"data,omitempty"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type CreateCollectionShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  *string    `json:"password" binding:"omitempty,min=8,max=72"`
+}
+
+// CollectionShareResponse.Token and URL are only set in the response to
+// creating the share; they cannot be recovered afterwards.
+type CollectionShareResponse struct {
+	ID           string  `json:"id"`
+	Token        string  `json:"token,omitempty"`
+	URL          string  `json:"url,omitempty"`
+	HasPassword  bool    `json:"has_password"`
+	ExpiresAt    *string `json:"expires_at"`
+	RevokedAt    *string `json:"revoked_at"`
+	ViewCount    int64   `json:"view_count"`
+	LastViewedAt *string `json:"last_viewed_at"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+type CollectionShareDetailResponse struct {
+	Success bool                     `json:"success"`
+	Message string                   `json:"message"`
+	Data    *CollectionShareResponse `json:"data,omitempty"`
+}
+
+type CollectionShareListResponse struct {
+	Success bool                      `json:"success"`
+	Message string                    `json:"message"`
+	Data    []CollectionShareResponse `json:"data"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type MessageResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
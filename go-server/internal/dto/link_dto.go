@@ -0,0 +1,89 @@
+package dto
+
+type CreateLinkRequest struct {
+	URL          string  `json:"url" binding:"required,url,max=2048"`
+	Source       *string `json:"source" binding:"omitempty,oneof=instagram facebook twitter tiktok youtube linkedin vimeo other"`
+	Title        *string `json:"title" binding:"omitempty,max=500"`
+	Category     *string `json:"category" binding:"omitempty,oneof=nature cooking food sports music tech entertainment other"`
+	ThumbnailURL *string `json:"thumbnail_url" binding:"omitempty,url,max=2048"`
+	Notes        *string `json:"notes" binding:"omitempty,max=5000"`
+	// Merge applies Title and Category to the existing link when the URL
+	// was already saved.
+	Merge bool `json:"merge"`
+}
+
+type ListLinksQuery struct {
+	PaginationQuery
+	Search   string `form:"search"`
+	Source   string `form:"source" binding:"omitempty,oneof=instagram facebook twitter tiktok youtube linkedin vimeo other"`
+	Category string `form:"category"`
+	// Tags accepts repeated or comma-separated names.
+	Tags    []string `form:"tags"`
+	TagMode string   `form:"tag_mode" binding:"omitempty,oneof=any all"`
+}
+
+type LinkResponse struct {
+	ID              string   `json:"id"`
+	URL             string   `json:"url"`
+	Source          string   `json:"source"`
+	Title           *string  `json:"title"`
+	Description     *string  `json:"description"`
+	AuthorName      *string  `json:"author_name"`
+	Notes           *string  `json:"notes"`
+	Category        *string  `json:"category"`
+	ThumbnailURL    *string  `json:"thumbnail_url"`
+	DurationSeconds *int     `json:"duration_seconds"`
+	Tags            []string `json:"tags"`
+	CreatedAt       string   `json:"created_at"`
+}
+
+type CreateLinkResponse struct {
+	Success   bool          `json:"success"`
+	Message   string        `json:"message"`
+	Duplicate bool          `json:"duplicate"`
+	Data      *LinkResponse `json:"data,omitempty"`
+}
+
+type LinkListResponse struct {
+	Success    bool           `json:"success"`
+	Message    string         `json:"message"`
+	Data       []LinkResponse `json:"data"`
+	Pagination *Pagination    `json:"pagination"`
+}
+
+type LinkSearchQuery struct {
+	PaginationQuery
+	Q        string `form:"q" binding:"required,max=200"`
+	Mode     string `form:"mode" binding:"omitempty,oneof=websearch phrase prefix"`
+	Source   string `form:"source" binding:"omitempty,oneof=instagram facebook twitter tiktok youtube linkedin vimeo other"`
+	Category string `form:"category"`
+}
+
+// LinkHighlights holds HTML-escaped text with matched terms wrapped in
+// <mark></mark>. Fields are nil when the text has no match.
+type LinkHighlights struct {
+	Title   *string `json:"title"`
+	Snippet *string `json:"snippet"`
+}
+
+type LinkSearchResult struct {
+	LinkResponse
+	Rank       float64        `json:"rank"`
+	Highlights LinkHighlights `json:"highlights"`
+}
+
+// LinkSearchResponse.MatchType is "fulltext" for word matches or "fuzzy"
+// when nothing matched and typo-tolerant results are shown instead.
+type LinkSearchResponse struct {
+	Success    bool               `json:"success"`
+	Message    string             `json:"message"`
+	MatchType  string             `json:"match_type"`
+	Data       []LinkSearchResult `json:"data"`
+	Pagination *Pagination        `json:"pagination"`
+}
+
+type LinkDetailResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Data    *LinkResponse `json:"data,omitempty"`
+}
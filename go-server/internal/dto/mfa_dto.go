@@ -0,0 +1,39 @@
+package dto
+
+type MFACodeRequest struct {
+	// Code is a six-digit authenticator code or a recovery code.
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+type MFASetupResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Data    *MFASetupData `json:"data"`
+}
+
+type MFASetupData struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	Success       bool     `json:"success"`
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Data    *MFAStatusData `json:"data"`
+}
+
+type MFAStatusData struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
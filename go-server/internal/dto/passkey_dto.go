@@ -0,0 +1,55 @@
+package dto
+
+import "encoding/json"
+
+type PasskeyRegisterRequest struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	// Name labels the passkey in the list, such as "iPhone".
+	Name string `json:"name" binding:"max=100"`
+	// Credential is the PublicKeyCredential from
+	// navigator.credentials.create, JSON-encoded.
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+type PasskeyLoginRequest struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	// Credential is the PublicKeyCredential from
+	// navigator.credentials.get, JSON-encoded.
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+type PasskeyOptionsResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Data    *PasskeyOptionsData `json:"data"`
+}
+
+type PasskeyOptionsData struct {
+	// Options is the publicKey argument for navigator.credentials.create
+	// or navigator.credentials.get.
+	Options interface{} `json:"options"`
+	// SessionToken goes back with the authenticator's response.
+	SessionToken string `json:"session_token"`
+}
+
+type PasskeyResponse struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Transports     []string `json:"transports"`
+	BackupEligible bool     `json:"backup_eligible"`
+	BackupState    bool     `json:"backup_state"`
+	CreatedAt      string   `json:"created_at"`
+	LastUsedAt     *string  `json:"last_used_at"`
+}
+
+type PasskeyCreatedResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Data    *PasskeyResponse `json:"data"`
+}
+
+type PasskeyListResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Data    []PasskeyResponse `json:"data"`
+}
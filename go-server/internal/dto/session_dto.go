@@ -0,0 +1,25 @@
+package dto
+
+// SessionResponse is one signed-in device. ID identifies the sign-in and
+// stays the same across token refreshes.
+type SessionResponse struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+}
+
+type SessionListResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Data    []SessionResponse `json:"data"`
+}
+
+type RevokeSessionsResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Revoked int64  `json:"revoked"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+type AddLinkTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1,max=20,dive,min=1,max=50"`
+}
+
+type TagAutocompleteQuery struct {
+	Prefix string `form:"prefix" binding:"max=50"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type TagResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	UsageCount int64  `json:"usage_count"`
+}
+
+type TagListResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Data    []TagResponse `json:"data"`
+}
+
+// TagDetailResponse.Merged is true when a rename hit an existing tag name
+// and the two tags were combined.
+type TagDetailResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Merged  bool         `json:"merged"`
+	Data    *TagResponse `json:"data,omitempty"`
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+}
+
+func NewAdminHandler(adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.adminService.GetUser(userID)
+	if err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateUserRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	if err := req.ValidateRole(); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	response, err := h.adminService.SetRole(actorID, userID, &req)
+	if err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrCannotChangeOwnRole):
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
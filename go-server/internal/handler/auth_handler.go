@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
 	"github.com/video-mobile-app/go-server/internal/constants"
 	"github.com/video-mobile-app/go-server/internal/dto"
 	"github.com/video-mobile-app/go-server/internal/models"
@@ -36,7 +39,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, accessToken, refreshToken, err := h.authService.Register(&req)
+	response, accessToken, refreshToken, err := h.authService.Register(&req, clientInfo(c))
 	if err != nil {
 		if err.Error() == "user with this email already exists" {
 			c.JSON(http.StatusConflict, gin.H{
@@ -62,18 +65,301 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, accessToken, refreshToken, err := h.authService.Login(&req)
+	response, accessToken, refreshToken, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": err.Error(),
+		var throttled *service.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			writeThrottled(c, throttled)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	writeSignIn(c, response, accessToken, refreshToken)
+}
+
+// VerifyMFA finishes a two-factor sign-in with the mfa_token from Login.
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.MFAVerifyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, accessToken, refreshToken, err := h.authService.VerifyMFA(&req, clientInfo(c))
+	if err != nil {
+		var throttled *service.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			writeThrottled(c, throttled)
+		case errors.Is(err, service.ErrInvalidMFAToken),
+			errors.Is(err, service.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	writeSignIn(c, response, accessToken, refreshToken)
+}
+
+func (h *AuthHandler) PasskeyLoginOptions(c *gin.Context) {
+	response, err := h.authService.PasskeyLoginOptions()
+	if err != nil {
+		handlePasskeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PasskeyLogin finishes a passkey sign-in started at PasskeyLoginOptions.
+func (h *AuthHandler) PasskeyLogin(c *gin.Context) {
+	var req dto.PasskeyLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, accessToken, refreshToken, err := h.authService.PasskeyLogin(&req, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPasskeyChallenge),
+			errors.Is(err, service.ErrPasskeyRejected):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			handlePasskeyError(c, err)
+		}
+		return
+	}
+
+	writeSignIn(c, response, accessToken, refreshToken)
+}
+
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req dto.MagicLinkRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, nonce, err := h.authService.RequestMagicLink(&req, c.GetHeader("Accept-Language"))
+	if err != nil {
+		var throttled *service.ThrottledError
+		if errors.As(err, &throttled) {
+			writeThrottled(c, throttled)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
 		})
 		return
 	}
 
-	utils.SetAuthCookies(c.Writer, accessToken, refreshToken)
+	utils.SetMagicLinkNonceCookie(c.Writer, nonce, int(service.MagicLinkTTL.Seconds()))
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *AuthHandler) MagicLinkLogin(c *gin.Context) {
+	var req dto.MagicLinkConsumeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	nonce, _ := c.Cookie(constants.CookieMagicLinkNonce)
+	response, accessToken, refreshToken, err := h.authService.MagicLinkLogin(&req, nonce, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMagicLink):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrMagicLinkOtherDevice):
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	utils.ClearMagicLinkNonceCookie(c.Writer)
+	writeSignIn(c, response, accessToken, refreshToken)
+}
+
+// writeSignIn sets the session cookies for a finished sign-in. A sign-in
+// waiting for a second factor only carries an mfa_token, so no cookies are
+// set.
+func writeSignIn(c *gin.Context, response *dto.AuthResponse, accessToken, refreshToken string) {
+	if !response.MFARequired {
+		utils.SetAuthCookies(c.Writer, accessToken, refreshToken)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GoogleLogin(c *gin.Context) {
+	var req dto.GoogleAuthRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, accessToken, refreshToken, err := h.authService.GoogleLogin(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGoogleSignInDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidGoogleToken),
+			errors.Is(err, service.ErrEmailNotVerified):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrOAuthAccountConflict):
+			c.JSON(http.StatusConflict, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	writeSignIn(c, response, accessToken, refreshToken)
+}
+
+// OAuthStart redirects the browser to the provider's consent page.
+func (h *AuthHandler) OAuthStart(c *gin.Context) {
+	authURL, stateToken, err := h.authService.OAuthStart(c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	utils.SetOAuthStateCookie(c.Writer, stateToken, int(service.OAuthStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthCallback is where the provider sends the browser back to. Apple
+// posts the callback as a form, everyone else uses the query string.
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	stateToken, _ := c.Cookie(constants.CookieOAuthState)
+	utils.ClearOAuthStateCookie(c.Writer)
+
+	if c.Request.FormValue("error") != "" {
+		h.oauthFailed(c, http.StatusUnauthorized, "access_denied", service.ErrOAuthExchangeFailed.Error())
+		return
+	}
+
+	response, accessToken, refreshToken, err := h.authService.OAuthCallback(
+		c.Request.Context(),
+		c.Param("provider"),
+		c.Request.FormValue("code"),
+		c.Request.FormValue("state"),
+		stateToken,
+		clientInfo(c),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownProvider):
+			h.oauthFailed(c, http.StatusNotFound, "unknown_provider", err.Error())
+		case errors.Is(err, service.ErrInvalidOAuthState):
+			h.oauthFailed(c, http.StatusBadRequest, "invalid_state", err.Error())
+		case errors.Is(err, service.ErrOAuthExchangeFailed):
+			h.oauthFailed(c, http.StatusUnauthorized, "exchange_failed", err.Error())
+		case errors.Is(err, service.ErrEmailNotVerified):
+			h.oauthFailed(c, http.StatusUnauthorized, "email_not_verified", err.Error())
+		case errors.Is(err, service.ErrOAuthAccountConflict):
+			h.oauthFailed(c, http.StatusConflict, "account_conflict", err.Error())
+		default:
+			h.oauthFailed(c, http.StatusInternalServerError, "server_error", "Internal server error")
+		}
+		return
+	}
+
+	redirectURL := config.AppConfig.OAuth.RedirectURL
+	if redirectURL == "" {
+		writeSignIn(c, response, accessToken, refreshToken)
+		return
+	}
+
+	if response.MFARequired {
+		// The app finishes the sign-in at /api/auth/mfa/verify.
+		target, err := url.Parse(redirectURL)
+		if err != nil {
+			h.oauthFailed(c, http.StatusInternalServerError, "server_error", "Internal server error")
+			return
+		}
+		query := target.Query()
+		query.Set("mfa_token", response.MFAToken)
+		target.RawQuery = query.Encode()
+		c.Redirect(http.StatusFound, target.String())
+		return
+	}
+
+	utils.SetAuthCookies(c.Writer, accessToken, refreshToken)
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// oauthFailed answers a failed browser sign-in, sending the user back to
+// the app with an error code when a redirect URL is configured.
+func (h *AuthHandler) oauthFailed(c *gin.Context, status int, code, message string) {
+	redirectURL := config.AppConfig.OAuth.RedirectURL
+	if redirectURL == "" {
+		c.JSON(status, gin.H{
+			"message": message,
+		})
+		return
+	}
+
+	target, err := url.Parse(redirectURL)
+	if err != nil {
+		c.JSON(status, gin.H{
+			"message": message,
+		})
+		return
+	}
+	query := target.Query()
+	query.Set("error", code)
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	refreshToken, err := c.Cookie(constants.CookieRefreshToken)
 	if err != nil || refreshToken == "" {
@@ -83,11 +369,23 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	accessToken, newRefreshToken, err := h.authService.RefreshToken(refreshToken)
+	accessToken, newRefreshToken, err := h.authService.RefreshToken(refreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": err.Error(),
-		})
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			utils.ClearAuthCookies(c.Writer)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
 		return
 	}
 
@@ -99,6 +397,28 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	// Cookie clients send the refresh token automatically; bearer clients
+	// may pass it in the body so it is revoked too.
+	refreshToken, err := c.Cookie(constants.CookieRefreshToken)
+	if err != nil || refreshToken == "" {
+		var req dto.LogoutRequest
+		if c.ShouldBindJSON(&req) == nil {
+			refreshToken = req.RefreshToken
+		}
+	}
+
+	if err := h.authService.Logout(claims, refreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
 	utils.ClearAuthCookies(c.Writer)
 	c.JSON(http.StatusOK, dto.TokenResponse{
 		Success: true,
@@ -107,19 +427,8 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 }
 
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": "Unauthorized",
-		})
-		return
-	}
-
-	userID, ok := userIDValue.(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Internal server error",
-		})
 		return
 	}
 
@@ -142,13 +451,192 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.authService.ListSessions(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeSession(claims, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Success: true,
+		Message: "Session revoked successfully",
+	})
+}
+
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.authService.RevokeOtherSessions(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.authService.UpdateProfile(userID, &req)
+	if err != nil {
+		var validationErr *dto.ValidationError
+		if errors.As(err, &validationErr) {
+			HandleValidationError(c, validationErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	if err := req.ValidatePassword(); err != nil {
+		HandleValidationError(c, err)
+		return
+	}
+
+	response, err := h.authService.ChangePassword(claims, &req)
+	if err != nil {
+		var validationErr *dto.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			HandleValidationError(c, validationErr)
+		case errors.Is(err, service.ErrIncorrectPassword),
+			errors.Is(err, service.ErrNoPasswordSet):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func mapUserToResponse(user *models.User) dto.UserResponse {
 	return dto.UserResponse{
-		ID:        user.ID.String(),
-		Name:      user.Name,
-		Email:     user.Email,
-		Avatar:    user.Avatar,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		ID:            user.ID.String(),
+		Name:          user.Name,
+		Email:         user.Email,
+		Avatar:        user.Avatar,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// currentUserID reads the user ID set by JWTAuthMiddleware. When it is missing
+// or malformed the error response is written and ok is false.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Unauthorized",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// currentClaims reads the access token claims set by JWTAuthMiddleware.
+func currentClaims(c *gin.Context) (*utils.Claims, bool) {
+	value, exists := c.Get("claims")
+	claims, ok := value.(*utils.Claims)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Unauthorized",
+		})
+		return nil, false
+	}
+	return claims, true
+}
+
+// clientInfo describes the requesting device for session tracking. The IP
+// comes from X-Forwarded-For only when the peer is a trusted proxy, so
+// clients cannot pick the IP sign-in lockouts count against.
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		Locale:    c.GetHeader("Accept-Language"),
 	}
 }
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type CollectionHandler struct {
+	collectionService service.CollectionService
+}
+
+func NewCollectionHandler(collectionService service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{
+		collectionService: collectionService,
+	}
+}
+
+func (h *CollectionHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateCollectionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.Create(userID, &req)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CollectionHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PaginationQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.List(userID, &query)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.collectionService.Get(userID, collectionID)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCollectionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.Update(userID, collectionID, &req)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.Delete(userID, collectionID); err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Success: true,
+		Message: "Collection deleted successfully",
+	})
+}
+
+func (h *CollectionHandler) ListItems(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query dto.PaginationQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.ListItems(userID, collectionID, &query)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionHandler) AddItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddCollectionItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.AddItem(userID, collectionID, &req)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CollectionHandler) RemoveItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseUUIDParam(c, "linkId")
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.RemoveItem(userID, collectionID, linkID); err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Success: true,
+		Message: "Link removed from collection",
+	})
+}
+
+func (h *CollectionHandler) MoveItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseUUIDParam(c, "linkId")
+	if !ok {
+		return
+	}
+
+	var req dto.MoveCollectionItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.collectionService.MoveItem(userID, collectionID, linkID, &req)
+	if err != nil {
+		handleCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleCollectionError(c *gin.Context, err error) {
+	var validationErr *dto.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		HandleValidationError(c, err)
+	case errors.Is(err, service.ErrCollectionNotFound),
+		errors.Is(err, service.ErrLinkNotFound),
+		errors.Is(err, service.ErrCollectionItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrCollectionItemExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
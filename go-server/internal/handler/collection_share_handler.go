@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type CollectionShareHandler struct {
+	shareService service.CollectionShareService
+}
+
+func NewCollectionShareHandler(shareService service.CollectionShareService) *CollectionShareHandler {
+	return &CollectionShareHandler{
+		shareService: shareService,
+	}
+}
+
+func (h *CollectionShareHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateCollectionShareRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.shareService.Create(userID, collectionID, &req)
+	if err != nil {
+		handleCollectionShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CollectionShareHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.shareService.List(userID, collectionID)
+	if err != nil {
+		handleCollectionShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionShareHandler) Revoke(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collectionID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	shareID, ok := parseUUIDParam(c, "shareId")
+	if !ok {
+		return
+	}
+
+	if err := h.shareService.Revoke(userID, collectionID, shareID); err != nil {
+		handleCollectionShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Success: true,
+		Message: "Share link revoked successfully",
+	})
+}
+
+func (h *CollectionShareHandler) GetShared(c *gin.Context) {
+	share, ok := currentShare(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.shareService.GetShared(share)
+	if err != nil {
+		handleCollectionShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionShareHandler) ListSharedItems(c *gin.Context) {
+	share, ok := currentShare(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PaginationQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.shareService.ListSharedItems(share, &query)
+	if err != nil {
+		handleCollectionShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// currentShare returns the share resolved by middleware.ShareTokenMiddleware.
+func currentShare(c *gin.Context) (*models.CollectionShare, bool) {
+	value, exists := c.Get("collectionShare")
+	share, ok := value.(*models.CollectionShare)
+	if !exists || !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": service.ErrShareNotFound.Error(),
+		})
+		return nil, false
+	}
+	return share, true
+}
+
+func handleCollectionShareError(c *gin.Context, err error) {
+	var validationErr *dto.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		HandleValidationError(c, err)
+	case errors.Is(err, service.ErrCollectionNotFound),
+		errors.Is(err, service.ErrShareNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
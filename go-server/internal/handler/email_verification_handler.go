@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type EmailVerificationHandler struct {
+	verificationService service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationService: verificationService,
+	}
+}
+
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.verificationService.Verify(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *EmailVerificationHandler) Resend(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.verificationService.Resend(userID, c.GetHeader("Accept-Language"))
+	if err != nil {
+		var throttled *service.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			writeThrottled(c, throttled)
+		case errors.Is(err, service.ErrEmailAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// writeThrottled answers 429 with a Retry-After header in whole seconds.
+func writeThrottled(c *gin.Context, err *service.ThrottledError) {
+	seconds := int((err.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"message":     err.Error(),
+		"retry_after": seconds,
+	})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type LinkHandler struct {
+	linkService service.LinkService
+}
+
+func NewLinkHandler(linkService service.LinkService) *LinkHandler {
+	return &LinkHandler{
+		linkService: linkService,
+	}
+}
+
+func (h *LinkHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateLinkRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.linkService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		if _, ok := err.(*dto.ValidationError); ok {
+			HandleValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	if response.Duplicate {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *LinkHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.ListLinksQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.linkService.List(userID, &query)
+	if err != nil {
+		if _, ok := err.(*dto.ValidationError); ok {
+			HandleValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *LinkHandler) Search(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.LinkSearchQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.linkService.Search(userID, &query)
+	if err != nil {
+		if _, ok := err.(*dto.ValidationError); ok {
+			HandleValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+func (h *MFAHandler) Status(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.mfaService.Status(userID)
+	if err != nil {
+		handleMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.mfaService.Setup(userID)
+	if err != nil {
+		handleMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.mfaService.Confirm(userID, &req)
+	if err != nil {
+		handleMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.mfaService.Disable(userID, &req)
+	if err != nil {
+		handleMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleMFAError(c *gin.Context, err error) {
+	var throttled *service.ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		writeThrottled(c, throttled)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrMFANotEnabled),
+		errors.Is(err, service.ErrMFASetupNotStarted),
+		errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
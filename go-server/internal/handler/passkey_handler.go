@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type PasskeyHandler struct {
+	passkeyService service.PasskeyService
+}
+
+func NewPasskeyHandler(passkeyService service.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+	}
+}
+
+func (h *PasskeyHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.passkeyService.List(userID)
+	if err != nil {
+		handlePasskeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PasskeyHandler) RegistrationOptions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.passkeyService.RegistrationOptions(userID)
+	if err != nil {
+		handlePasskeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PasskeyHandler) Register(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PasskeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.passkeyService.Register(userID, &req)
+	if err != nil {
+		handlePasskeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *PasskeyHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.passkeyService.Delete(userID, id); err != nil {
+		handlePasskeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Success: true,
+		Message: "Passkey removed successfully",
+	})
+}
+
+func handlePasskeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPasskeysDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidPasskeyChallenge),
+		errors.Is(err, service.ErrPasskeyRejected):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrPasskeyAlreadyRegistered):
+		c.JSON(http.StatusConflict, gin.H{
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrPasskeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
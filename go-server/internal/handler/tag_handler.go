@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+type TagHandler struct {
+	tagService service.TagService
+}
+
+func NewTagHandler(tagService service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+func (h *TagHandler) AddToLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddLinkTagsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.tagService.AddToLink(userID, linkID, &req)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TagHandler) RemoveFromLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	tagID, ok := parseUUIDParam(c, "tagId")
+	if !ok {
+		return
+	}
+
+	response, err := h.tagService.RemoveFromLink(userID, linkID, tagID)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.TagAutocompleteQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.tagService.Autocomplete(userID, &query)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TagHandler) Rename(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tagID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.RenameTagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorJSON(c, err)
+		return
+	}
+
+	response, err := h.tagService.Rename(userID, tagID, &req)
+	if err != nil {
+		handleTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleTagError(c *gin.Context, err error) {
+	var validationErr *dto.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		HandleValidationError(c, err)
+	case errors.Is(err, service.ErrTagNotFound),
+		errors.Is(err, service.ErrLinkNotFound),
+		errors.Is(err, service.ErrLinkTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Internal server error",
+		})
+	}
+}
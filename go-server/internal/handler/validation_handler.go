@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/video-mobile-app/go-server/internal/dto"
 	"github.com/video-mobile-app/go-server/internal/utils"
 )
@@ -25,3 +26,14 @@ func HandleValidationError(c *gin.Context, err error) {
 	}
 	utils.ValidationErrorJSON(c, err)
 }
+
+// parseUUIDParam reads a UUID path parameter. When it is malformed a
+// validation error is written and ok is false.
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		HandleValidationError(c, &dto.ValidationError{Field: name, Message: "Invalid value"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,167 @@
+// Package idtoken verifies OpenID Connect ID tokens, such as the ones
+// Google Sign-In hands to the mobile app.
+package idtoken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+	GoogleIssuer  = "https://accounts.google.com"
+)
+
+// GoogleIssuers are the iss values Google puts in its ID tokens.
+var GoogleIssuers = []string{GoogleIssuer, "accounts.google.com"}
+
+var (
+	ErrInvalidToken  = errors.New("idtoken: invalid token")
+	ErrInvalidIssuer = errors.New("idtoken: unexpected issuer")
+	ErrInvalidAud    = errors.New("idtoken: unexpected audience")
+)
+
+// Claims are the standard OIDC identity claims of a verified token.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Audience      []string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+	Nonce         string
+	ExpiresAt     time.Time
+}
+
+type Config struct {
+	Issuers   []string
+	Audiences []string
+	Keys      KeySource
+	// Leeway tolerates clock skew when checking exp, iat and nbf.
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+type Verifier struct {
+	cfg    Config
+	parser *jwt.Parser
+}
+
+func NewVerifier(cfg Config) *Verifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Now != nil {
+		options = append(options, jwt.WithTimeFunc(cfg.Now))
+	}
+
+	return &Verifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(options...),
+	}
+}
+
+// NewGoogleVerifier verifies Google ID tokens issued to any of clientIDs,
+// with keys from keys or, when keys is nil, from Google's JWKS endpoint.
+func NewGoogleVerifier(clientIDs []string, keys KeySource) *Verifier {
+	if keys == nil {
+		keys = NewRemoteKeySet(GoogleJWKSURL, nil)
+	}
+	return NewVerifier(Config{
+		Issuers:   GoogleIssuers,
+		Audiences: clientIDs,
+		Keys:      keys,
+		Leeway:    time.Minute,
+	})
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+	Nonce         string   `json:"nonce"`
+}
+
+// Verify checks the token's signature, expiry, issuer and audience and
+// returns its claims.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (*Claims, error) {
+	var claims tokenClaims
+	_, err := v.parser.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.cfg.Keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !contains(v.cfg.Issuers, claims.Issuer) {
+		return nil, ErrInvalidIssuer
+	}
+
+	audienceOK := false
+	for _, aud := range claims.Audience {
+		if contains(v.cfg.Audiences, aud) {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, ErrInvalidAud
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return &Claims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Audience:      claims.Audience,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		Nonce:         claims.Nonce,
+		ExpiresAt:     claims.ExpiresAt.Time,
+	}, nil
+}
+
+// flexBool accepts both true and "true"; some issuers send email_verified
+// as a string.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexBool(v)
+	case string:
+		*b = flexBool(v == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v != "" && v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package idtoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "client-123.apps.googleusercontent.com"
+
+type testIssuer struct {
+	server   *httptest.Server
+	keys     map[string]*rsa.PrivateKey
+	requests atomic.Int32
+}
+
+func newTestIssuer(t *testing.T, kids ...string) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		issuer.addKey(t, kid)
+	}
+
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.requests.Add(1)
+		var set struct {
+			Keys []map[string]string `json:"keys"`
+		}
+		for kid, key := range issuer.keys {
+			set.Keys = append(set.Keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	i.keys[kid] = key
+}
+
+func (i *testIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(i.keys[kid])
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            GoogleIssuer,
+		"aud":            testClientID,
+		"sub":            "110248495921238986420",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"picture":        "https://lh3.googleusercontent.com/a/photo",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyGoogleToken(t *testing.T) {
+	issuer := newTestIssuer(t, "key-1")
+	verifier := NewGoogleVerifier([]string{"other-client", testClientID}, NewRemoteKeySet(issuer.server.URL, nil))
+
+	claims, err := verifier.Verify(context.Background(), issuer.sign(t, "key-1", validClaims()))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "110248495921238986420" || claims.Email != "jane@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if claims.Name != "Jane Doe" || claims.Picture == "" {
+		t.Fatalf("profile claims missing: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	issuer := newTestIssuer(t, "key-1")
+	verifier := NewGoogleVerifier([]string{testClientID}, NewRemoteKeySet(issuer.server.URL, nil))
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		want   error
+	}{
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }, ErrInvalidAud},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, ErrInvalidIssuer},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, ErrInvalidToken},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }, ErrInvalidToken},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }, ErrInvalidToken},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.mutate(claims)
+			_, err := verifier.Verify(context.Background(), issuer.sign(t, "key-1", claims))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsForeignSignature(t *testing.T) {
+	trusted := newTestIssuer(t, "key-1")
+	attacker := newTestIssuer(t, "key-1")
+	verifier := NewGoogleVerifier([]string{testClientID}, NewRemoteKeySet(trusted.server.URL, nil))
+
+	_, err := verifier.Verify(context.Background(), attacker.sign(t, "key-1", validClaims()))
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRemoteKeySetCachesAndRefetchesOnRotation(t *testing.T) {
+	issuer := newTestIssuer(t, "key-1")
+	keys := NewRemoteKeySet(issuer.server.URL, nil)
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	verifier := NewGoogleVerifier([]string{testClientID}, keys)
+
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(context.Background(), issuer.sign(t, "key-1", validClaims())); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	}
+	if got := issuer.requests.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+
+	// The issuer rolls to a new key; the unknown kid forces a refetch once
+	// the minimum refresh interval has passed.
+	issuer.addKey(t, "key-2")
+	rotated := issuer.sign(t, "key-2", validClaims())
+	if _, err := verifier.Verify(context.Background(), rotated); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify before refresh interval = %v, want ErrInvalidToken", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := verifier.Verify(context.Background(), rotated); err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	if got := issuer.requests.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	if got := cacheTTL("public, max-age=19800, must-revalidate"); got != 19800*time.Second {
+		t.Errorf("cacheTTL = %v", got)
+	}
+	if got := cacheTTL("no-store"); got != defaultCacheTTL {
+		t.Errorf("cacheTTL without max-age = %v", got)
+	}
+}
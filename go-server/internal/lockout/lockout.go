@@ -0,0 +1,241 @@
+// Package lockout slows down password guessing. It counts failed sign-ins
+// per account and per client IP over a sliding window; past a few free
+// attempts each failure makes the next attempt wait twice as long, and
+// too many failures lock the account or IP out for a while.
+//
+// Every attempt is counted as a failure when it starts and forgiven when
+// it succeeds, so parallel attempts see each other: the store adds the
+// failure and reports the one before it in a single operation.
+package lockout
+
+import (
+	"log"
+	"time"
+)
+
+// Store keeps failed attempts and lockouts by key.
+type Store interface {
+	// Failures reports how many failures key has after since and when the
+	// latest one was.
+	Failures(key string, since time.Time) (int, time.Time, error)
+	// AddFailure records a failure at at and returns how many failures key
+	// has after since, this one included, and when the latest one before
+	// it was. Concurrent calls for a key must not see the same count.
+	// Failures older than since may be forgotten.
+	AddFailure(key string, at, since time.Time) (int, time.Time, error)
+	// RemoveLatest forgets key's most recent failure.
+	RemoveLatest(key string) error
+	// Lock bars key until until.
+	Lock(key string, until time.Time) error
+	// LockedUntil returns when key's lock ends, or the zero time when key
+	// is not locked at now.
+	LockedUntil(key string, now time.Time) (time.Time, error)
+	// Reset forgets key's failures.
+	Reset(key string) error
+}
+
+// Policy says how failures count against one kind of key.
+type Policy struct {
+	// FreeAttempts failures within the window cost nothing.
+	FreeAttempts int
+	// BaseDelay is the wait after the first failure past FreeAttempts. It
+	// doubles with each further failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutAfter failures within the window lock the key for
+	// LockoutDuration. Zero turns lockouts off.
+	LockoutAfter    int
+	LockoutDuration time.Duration
+}
+
+// delay is how long to wait after the failures-th failure in the window.
+func (p Policy) delay(failures int) time.Duration {
+	if failures <= p.FreeAttempts || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Scopes a lockout can apply to.
+const (
+	ScopeAccount = "account"
+	ScopeIP      = "ip"
+)
+
+// Event describes a lockout, for the audit log.
+type Event struct {
+	Scope string
+	// Subject is the locked account's email or the locked IP.
+	Subject string
+	// IP is the client whose failure caused the lockout.
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+}
+
+type Options struct {
+	// Window is how long a failure counts.
+	Window  time.Duration
+	Account Policy
+	IP      Policy
+	// Audit is told about each lockout. Lockouts are logged when nil.
+	Audit func(Event)
+}
+
+type Tracker struct {
+	store   Store
+	window  time.Duration
+	account Policy
+	ip      Policy
+	audit   func(Event)
+	now     func() time.Time
+}
+
+func NewTracker(store Store, opts Options) *Tracker {
+	audit := opts.Audit
+	if audit == nil {
+		audit = logEvent
+	}
+	return &Tracker{
+		store:   store,
+		window:  opts.Window,
+		account: opts.Account,
+		ip:      opts.IP,
+		audit:   audit,
+		now:     time.Now,
+	}
+}
+
+type trackedKey struct {
+	scope   string
+	subject string
+	policy  Policy
+}
+
+func (k trackedKey) String() string {
+	return k.scope + ":" + k.subject
+}
+
+func (t *Tracker) keys(account, ip string) []trackedKey {
+	keys := make([]trackedKey, 0, 2)
+	if account != "" {
+		keys = append(keys, trackedKey{ScopeAccount, account, t.account})
+	}
+	if ip != "" {
+		keys = append(keys, trackedKey{ScopeIP, ip, t.ip})
+	}
+	return keys
+}
+
+// Begin starts a sign-in to account from ip. It returns how long the
+// sign-in must wait instead, or zero when it may go ahead, in which case
+// the attempt already counts as a failure until Succeed forgives it.
+func (t *Tracker) Begin(account, ip string) (time.Duration, error) {
+	now := t.now()
+	keys := t.keys(account, ip)
+	var wait time.Duration
+
+	for _, key := range keys {
+		lockedUntil, err := t.store.LockedUntil(key.String(), now)
+		if err != nil {
+			return 0, err
+		}
+		if d := lockedUntil.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait, nil
+	}
+
+	reserved := make([]trackedKey, 0, len(keys))
+	for _, key := range keys {
+		failures, previous, err := t.store.AddFailure(key.String(), now, now.Add(-t.window))
+		if err != nil {
+			t.release(reserved)
+			return 0, err
+		}
+		reserved = append(reserved, key)
+
+		earlier := failures - 1
+		if d := previous.Add(key.policy.delay(earlier)).Sub(now); earlier > 0 && d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		// Waiting attempts are not tried, so they do not count either.
+		if err := t.release(reserved); err != nil {
+			return 0, err
+		}
+	}
+	return wait, nil
+}
+
+// Fail confirms that the attempt begun for account from ip failed,
+// locking either out when it has failed too often.
+func (t *Tracker) Fail(account, ip string) error {
+	now := t.now()
+
+	for _, key := range t.keys(account, ip) {
+		if key.policy.LockoutAfter <= 0 {
+			continue
+		}
+		failures, _, err := t.store.Failures(key.String(), now.Add(-t.window))
+		if err != nil {
+			return err
+		}
+		if failures < key.policy.LockoutAfter {
+			continue
+		}
+
+		lockedUntil := now.Add(key.policy.LockoutDuration)
+		if err := t.store.Lock(key.String(), lockedUntil); err != nil {
+			return err
+		}
+		t.audit(Event{
+			Scope:       key.scope,
+			Subject:     key.subject,
+			IP:          ip,
+			Failures:    failures,
+			LockedUntil: lockedUntil,
+		})
+	}
+	return nil
+}
+
+// Succeed forgets the account's failures after a correct password, and
+// the attempt counted against ip. The IP's earlier failures stay, so
+// signing in to one account does not buy more guesses at others.
+func (t *Tracker) Succeed(account, ip string) error {
+	if account != "" {
+		if err := t.store.Reset(trackedKey{scope: ScopeAccount, subject: account}.String()); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		return t.store.RemoveLatest(trackedKey{scope: ScopeIP, subject: ip}.String())
+	}
+	return nil
+}
+
+// release forgets the failures Begin counted for keys.
+func (t *Tracker) release(keys []trackedKey) error {
+	for _, key := range keys {
+		if err := t.store.RemoveLatest(key.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func logEvent(e Event) {
+	log.Printf("audit: login lockout of %s %q after %d failed attempts (last from %s), until %s",
+		e.Scope, e.Subject, e.Failures, e.IP, e.LockedUntil.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,206 @@
+package lockout
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestTracker(opts Options) (*Tracker, *time.Time, *[]Event) {
+	now := time.Now()
+	var events []Event
+	opts.Audit = func(e Event) { events = append(events, e) }
+
+	tracker := NewTracker(NewMemoryStore(), opts)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now, &events
+}
+
+func mustBegin(t *testing.T, tracker *Tracker, account, ip string) time.Duration {
+	t.Helper()
+	wait, err := tracker.Begin(account, ip)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	return wait
+}
+
+// mustFail begins an attempt that may go ahead and fails it.
+func mustFail(t *testing.T, tracker *Tracker, account, ip string) {
+	t.Helper()
+	if wait := mustBegin(t, tracker, account, ip); wait != 0 {
+		t.Fatalf("Begin before a failure: wait %v, want 0", wait)
+	}
+	if err := tracker.Fail(account, ip); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+}
+
+func TestPolicyDelayDoubles(t *testing.T) {
+	policy := Policy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for failures, expected := range want {
+		if got := policy.delay(failures); got != expected {
+			t.Errorf("delay(%d) = %v, want %v", failures, got, expected)
+		}
+	}
+}
+
+func TestTrackerBacksOffAfterFreeAttempts(t *testing.T) {
+	tracker, now, _ := newTestTracker(Options{
+		Window:  time.Hour,
+		Account: Policy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: time.Minute},
+	})
+
+	mustFail(t, tracker, "a@example.com", "")
+	mustFail(t, tracker, "a@example.com", "")
+	mustFail(t, tracker, "a@example.com", "")
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != time.Second {
+		t.Fatalf("wait after 3 failures = %v, want 1s", wait)
+	}
+
+	*now = now.Add(time.Second)
+	mustFail(t, tracker, "a@example.com", "")
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != 2*time.Second {
+		t.Fatalf("wait after 4 failures = %v, want 2s", wait)
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != 1500*time.Millisecond {
+		t.Fatalf("wait half a second later = %v, want 1.5s", wait)
+	}
+
+	if wait := mustBegin(t, tracker, "b@example.com", ""); wait != 0 {
+		t.Fatalf("other account must not wait, got %v", wait)
+	}
+}
+
+func TestTrackerCountsAttemptsInFlight(t *testing.T) {
+	tracker, _, _ := newTestTracker(Options{
+		Window:  time.Hour,
+		Account: Policy{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	})
+
+	// Parallel attempts that have not failed yet still count, so they
+	// cannot all slip past the backoff.
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if wait, err := tracker.Begin("a@example.com", ""); err == nil && wait == 0 {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 4 {
+		t.Fatalf("parallel attempts allowed = %d, want 4", got)
+	}
+}
+
+func TestTrackerLocksOutAndAudits(t *testing.T) {
+	tracker, now, events := newTestTracker(Options{
+		Window:  time.Hour,
+		Account: Policy{FreeAttempts: 100, LockoutAfter: 3, LockoutDuration: 15 * time.Minute},
+	})
+
+	for i := 0; i < 2; i++ {
+		mustFail(t, tracker, "a@example.com", "203.0.113.7")
+	}
+	if len(*events) != 0 {
+		t.Fatalf("audit events before the lockout: %+v", *events)
+	}
+
+	mustFail(t, tracker, "a@example.com", "203.0.113.7")
+	if wait := mustBegin(t, tracker, "a@example.com", "198.51.100.1"); wait != 15*time.Minute {
+		t.Fatalf("wait after lockout = %v, want 15m", wait)
+	}
+
+	if len(*events) != 1 {
+		t.Fatalf("audit events = %+v, want one", *events)
+	}
+	event := (*events)[0]
+	if event.Scope != ScopeAccount || event.Subject != "a@example.com" || event.IP != "203.0.113.7" ||
+		event.Failures != 3 || !event.LockedUntil.Equal(now.Add(15*time.Minute)) {
+		t.Fatalf("audit event = %+v", event)
+	}
+
+	*now = now.Add(15 * time.Minute)
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != 0 {
+		t.Fatalf("wait after the lockout ended = %v, want 0", wait)
+	}
+}
+
+func TestTrackerCountsFailuresPerIPAcrossAccounts(t *testing.T) {
+	tracker, _, events := newTestTracker(Options{
+		Window:  time.Hour,
+		Account: Policy{FreeAttempts: 100},
+		IP:      Policy{FreeAttempts: 100, LockoutAfter: 3, LockoutDuration: time.Minute},
+	})
+
+	mustFail(t, tracker, "a@example.com", "203.0.113.7")
+	mustFail(t, tracker, "b@example.com", "203.0.113.7")
+	mustFail(t, tracker, "c@example.com", "203.0.113.7")
+
+	if wait := mustBegin(t, tracker, "d@example.com", "203.0.113.7"); wait != time.Minute {
+		t.Fatalf("wait for a new account from a locked IP = %v, want 1m", wait)
+	}
+	if wait := mustBegin(t, tracker, "d@example.com", "198.51.100.1"); wait != 0 {
+		t.Fatalf("wait from another IP = %v, want 0", wait)
+	}
+	if len(*events) != 1 || (*events)[0].Scope != ScopeIP || (*events)[0].Subject != "203.0.113.7" {
+		t.Fatalf("audit events = %+v", *events)
+	}
+}
+
+func TestTrackerSucceedResetsOnlyTheAccount(t *testing.T) {
+	tracker, now, _ := newTestTracker(Options{
+		Window:  time.Hour,
+		Account: Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		IP:      Policy{FreeAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour},
+	})
+
+	mustFail(t, tracker, "a@example.com", "203.0.113.7")
+	mustFail(t, tracker, "a@example.com", "203.0.113.7")
+	*now = now.Add(time.Minute)
+	if wait := mustBegin(t, tracker, "a@example.com", "203.0.113.7"); wait != 0 {
+		t.Fatalf("wait a minute later = %v, want 0", wait)
+	}
+	if err := tracker.Succeed("a@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != 0 {
+		t.Fatalf("account wait after success = %v, want 0", wait)
+	}
+	// The successful attempt is forgiven, but the IP's two failures stay:
+	// one more failure is free, the next waits.
+	mustFail(t, tracker, "", "203.0.113.7")
+	if wait := mustBegin(t, tracker, "", "203.0.113.7"); wait != time.Hour {
+		t.Fatalf("IP wait after success = %v, want 1h", wait)
+	}
+}
+
+func TestTrackerWindowSlides(t *testing.T) {
+	tracker, now, _ := newTestTracker(Options{
+		Window:  10 * time.Minute,
+		Account: Policy{FreeAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour},
+	})
+
+	mustFail(t, tracker, "a@example.com", "")
+	*now = now.Add(6 * time.Minute)
+	mustFail(t, tracker, "a@example.com", "")
+	*now = now.Add(6 * time.Minute)
+
+	// The first failure has left the window, so this one is only the
+	// second.
+	mustFail(t, tracker, "a@example.com", "")
+	if wait := mustBegin(t, tracker, "a@example.com", ""); wait != 0 {
+		t.Fatalf("wait = %v, want 0 once old failures leave the window", wait)
+	}
+}
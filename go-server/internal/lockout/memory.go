@@ -0,0 +1,118 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	locks    map[string]time.Time
+}
+
+// NewMemoryStore returns a process-local Store, for tests and single
+// instance setups.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		failures: make(map[string][]time.Time),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (s *memoryStore) Failures(key string, since time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recent := s.recent(key, since)
+	if len(recent) == 0 {
+		return 0, time.Time{}, nil
+	}
+	return len(recent), recent[len(recent)-1], nil
+}
+
+func (s *memoryStore) AddFailure(key string, at, since time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Keys whose failures have all aged out are dropped here rather than
+	// in a separate job.
+	for other := range s.failures {
+		if len(s.recent(other, since)) == 0 {
+			delete(s.failures, other)
+		}
+	}
+
+	recent := s.recent(key, since)
+	var previous time.Time
+	if len(recent) > 0 {
+		previous = recent[len(recent)-1]
+	}
+	recent = append(recent, at)
+	s.failures[key] = recent
+	return len(recent), previous, nil
+}
+
+func (s *memoryStore) RemoveLatest(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.failures[key]
+	switch len(failures) {
+	case 0:
+	case 1:
+		delete(s.failures, key)
+	default:
+		s.failures[key] = failures[:len(failures)-1]
+	}
+	return nil
+}
+
+// recent drops key's failures up to since and returns the rest, oldest
+// first. The caller holds s.mu.
+func (s *memoryStore) recent(key string, since time.Time) []time.Time {
+	failures := s.failures[key]
+	i := 0
+	for i < len(failures) && !failures[i].After(since) {
+		i++
+	}
+	if i > 0 {
+		failures = failures[i:]
+		s.failures[key] = failures
+	}
+	return failures
+}
+
+func (s *memoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for other, lockedUntil := range s.locks {
+		if !lockedUntil.After(now) {
+			delete(s.locks, other)
+		}
+	}
+
+	s.locks[key] = until
+	return nil
+}
+
+func (s *memoryStore) LockedUntil(key string, now time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockedUntil, ok := s.locks[key]
+	if !ok || !lockedUntil.After(now) {
+		return time.Time{}, nil
+	}
+	return lockedUntil, nil
+}
+
+func (s *memoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	return nil
+}
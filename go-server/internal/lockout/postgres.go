@@ -0,0 +1,104 @@
+package lockout
+
+import (
+	"errors"
+	"time"
+
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore returns a Store backed by the login_failures and
+// login_lockouts tables, shared by every server instance.
+func NewPostgresStore(db *gorm.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Failures(key string, since time.Time) (int, time.Time, error) {
+	var result struct {
+		Count  int
+		Latest *time.Time
+	}
+	err := s.db.Model(&models.LoginFailure{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS latest").
+		Where("key = ? AND created_at > ?", key, since).
+		Scan(&result).Error
+	if err != nil || result.Latest == nil {
+		return 0, time.Time{}, err
+	}
+	return result.Count, *result.Latest, nil
+}
+
+func (s *postgresStore) AddFailure(key string, at, since time.Time) (int, time.Time, error) {
+	var count int64
+	var previous time.Time
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Attempts on one key queue here, so each sees the ones before it.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+		// Failures are useless once they leave the window; drop them here
+		// rather than in a separate job.
+		if err := tx.Where("created_at <= ?", since).Delete(&models.LoginFailure{}).Error; err != nil {
+			return err
+		}
+
+		var latest *time.Time
+		if err := tx.Model(&models.LoginFailure{}).
+			Select("MAX(created_at)").
+			Where("key = ?", key).
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		if latest != nil {
+			previous = *latest
+		}
+
+		if err := tx.Create(&models.LoginFailure{Key: key, CreatedAt: at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.LoginFailure{}).
+			Where("key = ? AND created_at > ?", key, since).
+			Count(&count).Error
+	})
+	return int(count), previous, err
+}
+
+func (s *postgresStore) RemoveLatest(key string) error {
+	return s.db.Exec(`DELETE FROM login_failures WHERE id = (
+		SELECT id FROM login_failures WHERE key = ? ORDER BY created_at DESC, id DESC LIMIT 1
+	)`, key).Error
+}
+
+func (s *postgresStore) Lock(key string, until time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("locked_until <= ?", time.Now()).Delete(&models.LoginLockout{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"locked_until"}),
+		}).Create(&models.LoginLockout{Key: key, LockedUntil: until}).Error
+	})
+}
+
+func (s *postgresStore) LockedUntil(key string, now time.Time) (time.Time, error) {
+	var lockout models.LoginLockout
+	err := s.db.Where("key = ? AND locked_until > ?", key, now).First(&lockout).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return lockout.LockedUntil, nil
+}
+
+func (s *postgresStore) Reset(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.LoginFailure{}).Error
+}
@@ -0,0 +1,84 @@
+// Package mailer sends transactional email through SMTP, or records it in
+// the log or an outbox directory during development and tests.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+const (
+	ModeLog    = "log"
+	ModeOutbox = "outbox"
+	ModeSMTP   = "smtp"
+)
+
+// Message is a single email. HTML is optional; Text is always sent.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Options selects and configures a Mailer for New.
+type Options struct {
+	// Mode is ModeLog, ModeOutbox or ModeSMTP. Defaults to ModeLog.
+	Mode string
+	// From is the sender, e.g. "Video Vault <no-reply@example.com>".
+	From      string
+	SMTP      SMTPOptions
+	OutboxDir string
+	// LogBodies makes ModeLog print message bodies. They hold sign-in and
+	// password reset links, so only turn it on in development.
+	LogBodies bool
+}
+
+// New builds the Mailer for opts.Mode.
+func New(opts Options) (Mailer, error) {
+	switch opts.Mode {
+	case "", ModeLog:
+		mail := NewLogMailer(nil)
+		mail.LogBodies = opts.LogBodies
+		return mail, nil
+	case ModeOutbox:
+		return NewOutboxMailer(opts.OutboxDir, opts.From)
+	case ModeSMTP:
+		return NewSMTPMailer(opts.SMTP, opts.From)
+	default:
+		return nil, fmt.Errorf("mailer: unknown mode %q", opts.Mode)
+	}
+}
+
+// LogMailer writes messages to a logger instead of delivering them, for
+// development.
+type LogMailer struct {
+	logger *log.Logger
+	// LogBodies prints the text of each message too; otherwise only the
+	// recipient and subject are logged, so links in it cannot be read
+	// from the log.
+	LogBodies bool
+}
+
+// NewLogMailer logs through logger, or the standard logger when nil. It
+// leaves message bodies out until LogBodies is set.
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if !m.LogBodies {
+		m.logger.Printf("Email to %s: %s (body not logged)", msg.To, msg.Subject)
+		return nil
+	}
+	m.logger.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
@@ -0,0 +1,235 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = &Message{
+	To:      "Ana <ana@example.com>",
+	Subject: "Reset your password",
+	Text:    "Hi Ana,\nopen the link.",
+	HTML:    "<p>Hi Ana,</p>",
+}
+
+func TestOutboxMailerWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewOutboxMailer(dir, "Video Vault <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("NewOutboxMailer: %v", err)
+	}
+
+	if err := m.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("outbox files = %v, %v; want one", files, err)
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertMessage(t, string(raw))
+}
+
+func TestOutboxMailerRejectsHeaderInjection(t *testing.T) {
+	m, err := NewOutboxMailer(t.TempDir(), "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("NewOutboxMailer: %v", err)
+	}
+
+	for _, msg := range []*Message{
+		{To: "ana@example.com\r\nBcc: eve@example.com", Subject: "Hi", Text: "x"},
+		{To: "ana@example.com", Subject: "Hi\r\nBcc: eve@example.com", Text: "x"},
+	} {
+		if err := m.Send(context.Background(), msg); err == nil {
+			t.Errorf("Send(%q, %q) succeeded", msg.To, msg.Subject)
+		}
+	}
+}
+
+func TestSMTPMailerDelivers(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNumber, _ := strconv.Atoi(port)
+	m, err := NewSMTPMailer(SMTPOptions{Host: host, Port: portNumber}, "Video Vault <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Send(ctx, testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-server.received
+	if got.from != "<no-reply@example.com>" || got.to != "<ana@example.com>" {
+		t.Errorf("envelope = %q -> %q", got.from, got.to)
+	}
+	assertMessage(t, got.data)
+}
+
+func TestNewRejectsUnknownMode(t *testing.T) {
+	if _, err := New(Options{Mode: "carrier-pigeon"}); err == nil {
+		t.Error("New with an unknown mode succeeded")
+	}
+	if _, err := New(Options{Mode: ModeSMTP, From: "no-reply@example.com"}); err == nil {
+		t.Error("New in SMTP mode without a host succeeded")
+	}
+}
+
+func assertMessage(t *testing.T, raw string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v\n%s", err, raw)
+	}
+	if got := msg.Header.Get("To"); got != `"Ana" <ana@example.com>` {
+		t.Errorf("To = %q", got)
+	}
+	if got := msg.Header.Get("Subject"); got != "Reset your password" {
+		t.Errorf("Subject = %q", got)
+	}
+
+	contentType := msg.Header.Get("Content-Type")
+	boundary := contentType[strings.Index(contentType, `boundary="`)+len(`boundary="`) : len(contentType)-1]
+	reader := multipart.NewReader(msg.Body, boundary)
+
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get("Content-Type")+"|"+string(body))
+	}
+
+	want := []string{
+		"text/plain; charset=utf-8|Hi Ana,\r\nopen the link.",
+		"text/html; charset=utf-8|<p>Hi Ana,</p>",
+	}
+	if strings.Join(parts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("parts = %q, want %q", parts, want)
+	}
+}
+
+type receivedMail struct {
+	from, to, data string
+}
+
+type fakeSMTPServer struct {
+	addr     string
+	received chan receivedMail
+}
+
+// newFakeSMTPServer accepts one plaintext SMTP session and records the
+// message it carries.
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{addr: listener.Addr().String(), received: make(chan receivedMail, 1)}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		var got receivedMail
+
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-fake")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				got.from = strings.Fields(strings.TrimPrefix(command, "MAIL FROM:"))[0]
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				got.to = strings.TrimPrefix(command, "RCPT TO:")
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				got.data = data.String()
+				reply("250 Queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				server.received <- got
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+	return server
+}
+
+func TestLogMailerLeavesOutBodiesUnlessAsked(t *testing.T) {
+	var out bytes.Buffer
+	mail := NewLogMailer(log.New(&out, "", 0))
+	msg := &Message{To: "a@example.com", Subject: "Sign in", Text: "https://example.com/?token=secret"}
+
+	if err := mail.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Fatalf("body logged: %q", out.String())
+	}
+	if !strings.Contains(out.String(), "a@example.com") {
+		t.Fatalf("recipient not logged: %q", out.String())
+	}
+
+	mail.LogBodies = true
+	if err := mail.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(out.String(), "secret") {
+		t.Fatalf("body not logged with LogBodies: %q", out.String())
+	}
+}
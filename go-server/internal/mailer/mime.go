@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("mailer: invalid message")
+
+// addresses parses the sender and recipient, rejecting anything that
+// could inject extra headers.
+func addresses(from, to string) (*mail.Address, *mail.Address, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: from address: %v", ErrInvalidMessage, err)
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return nil, nil, fmt.Errorf("%w: recipient contains a line break", ErrInvalidMessage)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: recipient: %v", ErrInvalidMessage, err)
+	}
+	return sender, recipient, nil
+}
+
+// encode renders msg as an RFC 5322 message, multipart/alternative when it
+// has an HTML part.
+func encode(from, to *mail.Address, msg *Message, now time.Time) ([]byte, error) {
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("%w: subject contains a line break", ErrInvalidMessage)
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+id+"@"+domain+">")
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := randomHex(12)
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		header("Content-Type", part.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.ReplaceAll(body, "\n", "\r\n")
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutboxMailer writes each message to an .eml file in a directory instead
+// of delivering it, so development and test mail can be opened in a mail
+// client or inspected by tests.
+type OutboxMailer struct {
+	dir  string
+	from *mail.Address
+	now  func() time.Time
+}
+
+func NewOutboxMailer(dir, from string) (*OutboxMailer, error) {
+	if dir == "" {
+		return nil, errors.New("mailer: outbox directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mailer: %w", err)
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: from address: %w", err)
+	}
+	return &OutboxMailer{dir: dir, from: sender, now: time.Now}, nil
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg *Message) error {
+	from, to, err := addresses(m.from.String(), msg.To)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	body, err := encode(from, to, msg, now)
+	if err != nil {
+		return err
+	}
+
+	suffix, err := randomHex(4)
+	if err != nil {
+		return err
+	}
+	name := now.UTC().Format("20060102T150405.000000000") + "-" + suffix + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
@@ -0,0 +1,118 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPOptions configures delivery through an SMTP relay.
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// ImplicitTLS connects over TLS from the start (usually port 465)
+	// instead of upgrading with STARTTLS.
+	ImplicitTLS bool
+}
+
+// SMTPMailer delivers messages to an SMTP relay, upgrading the connection
+// with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	opts SMTPOptions
+	from *mail.Address
+	now  func() time.Time
+}
+
+func NewSMTPMailer(opts SMTPOptions, from string) (*SMTPMailer, error) {
+	if opts.Host == "" {
+		return nil, errors.New("mailer: SMTP host is required")
+	}
+	if opts.Port == 0 {
+		opts.Port = 587
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: from address: %w", err)
+	}
+	return &SMTPMailer{opts: opts, from: sender, now: time.Now}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, to, err := addresses(m.from.String(), msg.To)
+	if err != nil {
+		return err
+	}
+	body, err := encode(from, to, msg, m.now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("mailer: connect: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, m.opts.Host)
+	if err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	defer client.Close()
+
+	if !m.opts.ImplicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.opts.Host}); err != nil {
+				return fmt.Errorf("mailer: starttls: %w", err)
+			}
+		}
+	}
+	if m.opts.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted
+		// connection to anything but localhost.
+		auth := smtp.PlainAuth("", m.opts.Username, m.opts.Password, m.opts.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("mailer: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.opts.Host, strconv.Itoa(m.opts.Port))
+	if m.opts.ImplicitTLS {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.opts.Host}}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
@@ -0,0 +1,185 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// DefaultLocale is used when none of the requested locales has a template.
+const DefaultLocale = "en"
+
+var ErrTemplateNotFound = errors.New("mailer: template not found")
+
+//go:embed templates
+var templateFS embed.FS
+
+// DefaultTemplates returns the templates bundled with the server.
+func DefaultTemplates() fs.FS {
+	sub, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Renderer builds messages from templates laid out as
+// <locale>/<name>.txt and an optional <locale>/<name>.html. The text
+// template must define a "subject" block.
+type Renderer struct {
+	fsys          fs.FS
+	defaultLocale string
+
+	mu    sync.Mutex
+	cache map[string]*localeTemplates
+}
+
+type localeTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewRenderer reads templates from fsys, falling back to defaultLocale.
+func NewRenderer(fsys fs.FS, defaultLocale string) *Renderer {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	return &Renderer{
+		fsys:          fsys,
+		defaultLocale: strings.ToLower(defaultLocale),
+		cache:         make(map[string]*localeTemplates),
+	}
+}
+
+// Render fills the named template with data for the first locale that has
+// it. locale may be a single tag ("pt-BR") or an Accept-Language value;
+// region tags fall back to their language, and everything falls back to
+// the default locale.
+func (r *Renderer) Render(name, locale string, data interface{}) (*Message, error) {
+	for _, candidate := range r.candidates(locale) {
+		tmpl, err := r.load(candidate, name)
+		if errors.Is(err, ErrTemplateNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return tmpl.execute(data)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+func (t *localeTemplates) execute(data interface{}) (*Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if t.html != nil {
+		if err := t.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+	}
+	return &Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+func (r *Renderer) candidates(locale string) []string {
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			candidates = append(candidates, tag)
+		}
+	}
+
+	for _, part := range strings.Split(locale, ",") {
+		tag := strings.TrimSpace(part)
+		if i := strings.IndexByte(tag, ';'); i >= 0 {
+			tag = strings.TrimSpace(tag[:i])
+		}
+		tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+		if tag == "*" || strings.ContainsAny(tag, "./\\") {
+			continue
+		}
+		add(tag)
+		if i := strings.IndexByte(tag, '-'); i > 0 {
+			add(tag[:i])
+		}
+	}
+	add(r.defaultLocale)
+	return candidates
+}
+
+func (r *Renderer) load(locale, name string) (*localeTemplates, error) {
+	key := locale + "/" + name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tmpl, ok := r.cache[key]; ok {
+		if tmpl == nil {
+			return nil, ErrTemplateNotFound
+		}
+		return tmpl, nil
+	}
+
+	tmpl, err := r.parse(key)
+	if errors.Is(err, ErrTemplateNotFound) {
+		r.cache[key] = nil
+	} else if err == nil {
+		r.cache[key] = tmpl
+	}
+	return tmpl, err
+}
+
+// htmlFuncs are available to HTML templates. safeURL marks a link the
+// server built itself as trusted, so app deep links such as
+// video-mobile-application://... are not rewritten by html/template.
+var htmlFuncs = htmltemplate.FuncMap{
+	"safeURL": func(s string) htmltemplate.URL {
+		return htmltemplate.URL(s)
+	},
+}
+
+func (r *Renderer) parse(key string) (*localeTemplates, error) {
+	textSource, err := fs.ReadFile(r.fsys, key+".txt")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.New(key + ".txt").Parse(string(textSource))
+	if err != nil {
+		return nil, err
+	}
+	if text.Lookup("subject") == nil {
+		return nil, fmt.Errorf("mailer: %s.txt does not define a subject", key)
+	}
+
+	tmpl := &localeTemplates{text: text}
+
+	htmlSource, err := fs.ReadFile(r.fsys, key+".html")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		tmpl.html, err = htmltemplate.New(key + ".html").Funcs(htmlFuncs).Parse(string(htmlSource))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
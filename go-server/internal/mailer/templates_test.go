@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRendererLocaleFallback(t *testing.T) {
+	renderer := NewRenderer(DefaultTemplates(), "en")
+	data := map[string]interface{}{
+		"Name":             "Ana",
+		"Link":             "video-mobile-application://reset-password?token=abc",
+		"ExpiresInMinutes": 30,
+	}
+
+	tests := []struct {
+		locale  string
+		subject string
+	}{
+		{"", "Reset your password"},
+		{"es", "Restablece tu contraseña"},
+		{"es-MX", "Restablece tu contraseña"},
+		{"fr-FR,es;q=0.8", "Restablece tu contraseña"},
+		{"de", "Reset your password"},
+		{"../es", "Reset your password"},
+	}
+	for _, tt := range tests {
+		msg, err := renderer.Render("password_reset", tt.locale, data)
+		if err != nil {
+			t.Fatalf("Render(%q): %v", tt.locale, err)
+		}
+		if msg.Subject != tt.subject {
+			t.Errorf("Render(%q) subject = %q, want %q", tt.locale, msg.Subject, tt.subject)
+		}
+	}
+}
+
+func TestRendererTextAndHTML(t *testing.T) {
+	renderer := NewRenderer(DefaultTemplates(), "en")
+	msg, err := renderer.Render("password_reset", "en", map[string]interface{}{
+		"Name":             "<b>Ana</b>",
+		"Link":             "video-mobile-application://reset-password?token=abc",
+		"ExpiresInMinutes": 30,
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	if !strings.Contains(msg.Text, "Hi <b>Ana</b>,") || !strings.Contains(msg.Text, "expires in 30 minutes") {
+		t.Errorf("unexpected text body:\n%s", msg.Text)
+	}
+	if strings.Contains(msg.HTML, "<b>Ana</b>") || !strings.Contains(msg.HTML, "&lt;b&gt;Ana&lt;/b&gt;") {
+		t.Errorf("HTML body did not escape the name:\n%s", msg.HTML)
+	}
+	if !strings.Contains(msg.HTML, `href="video-mobile-application://reset-password?token=abc"`) {
+		t.Errorf("HTML body lost the app link:\n%s", msg.HTML)
+	}
+}
+
+func TestRendererTextOnlyAndMissing(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/notice.txt": {Data: []byte(`{{define "subject"}}  Notice  {{end}}Hello {{.}}`)},
+		"en/broken.txt": {Data: []byte(`no subject`)},
+	}
+	renderer := NewRenderer(fsys, "")
+
+	msg, err := renderer.Render("notice", "en-GB", "world")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if msg.Subject != "Notice" || msg.Text != "Hello world\n" || msg.HTML != "" {
+		t.Errorf("Render = %+v", msg)
+	}
+
+	if _, err := renderer.Render("missing", "en", nil); err == nil {
+		t.Error("Render of a missing template succeeded")
+	}
+	if _, err := renderer.Render("broken", "en", nil); err == nil {
+		t.Error("Render of a template without a subject succeeded")
+	}
+}
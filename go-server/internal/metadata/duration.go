@@ -0,0 +1,57 @@
+package metadata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration parses the subset of ISO 8601 durations used by
+// schema.org VideoObject, e.g. "PT1H2M3S" or "P1DT30M".
+func parseISODuration(value string) time.Duration {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" || value == "P" || value == "PT" {
+		return 0
+	}
+
+	match := isoDurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+
+	var total time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute}
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0
+		}
+		total += time.Duration(n) * unit
+	}
+
+	if match[4] != "" {
+		seconds, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			return 0
+		}
+		total += time.Duration(seconds * float64(time.Second))
+	}
+
+	return total
+}
+
+// parseSeconds parses durations given as a plain number of seconds, as in
+// og:video:duration.
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,160 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout      = 8 * time.Second
+	DefaultMaxBodyBytes = 1 << 20
+
+	// DefaultUserAgent is browser-like so Instagram, Facebook and friends are
+	// more likely to serve full HTML with Open Graph tags.
+	DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+type Options struct {
+	// Client is used for all requests. Defaults to a client that only
+	// connects to public addresses; a custom Client must guard against
+	// internal addresses itself.
+	Client *http.Client
+	// Timeout bounds a whole Fetch call, including any oEmbed follow-up.
+	Timeout time.Duration
+	// MaxBodyBytes caps how much of each response body is read.
+	MaxBodyBytes int64
+	UserAgent    string
+}
+
+type httpFetcher struct {
+	client       *http.Client
+	timeout      time.Duration
+	maxBodyBytes int64
+	userAgent    string
+}
+
+func NewFetcher(opts Options) Fetcher {
+	f := &httpFetcher{
+		client:       opts.Client,
+		timeout:      opts.Timeout,
+		maxBodyBytes: opts.MaxBodyBytes,
+		userAgent:    opts.UserAgent,
+	}
+	if f.client == nil {
+		f.client = newSafeClient()
+	}
+	if f.timeout <= 0 {
+		f.timeout = DefaultTimeout
+	}
+	if f.maxBodyBytes <= 0 {
+		f.maxBodyBytes = DefaultMaxBodyBytes
+	}
+	if f.userAgent == "" {
+		f.userAgent = DefaultUserAgent
+	}
+	return f
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, ErrUnsupportedScheme
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	resp, err := f.get(ctx, target.String(), "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	finalURL := resp.Request.URL
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	// Links straight to an image are their own thumbnail.
+	if strings.HasPrefix(mediaType, "image/") {
+		return &Metadata{ThumbnailURL: finalURL.String()}, nil
+	}
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return &Metadata{}, nil
+	}
+
+	doc := parseDocument(io.LimitReader(resp.Body, f.maxBodyBytes))
+	result := doc.metadata(finalURL)
+
+	if doc.oembedURL != "" && (result.Title == "" || result.ThumbnailURL == "" || result.Author == "") {
+		if oembedURL := resolveURL(finalURL, doc.oembedURL); oembedURL != "" {
+			if embed, err := f.fetchOEmbed(ctx, oembedURL); err == nil {
+				result.merge(embed)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+type oembedResponse struct {
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Duration     int    `json:"duration"`
+}
+
+func (f *httpFetcher) fetchOEmbed(ctx context.Context, endpoint string) (*Metadata, error) {
+	resp, err := f.get(ctx, endpoint, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var embed oembedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, f.maxBodyBytes)).Decode(&embed); err != nil {
+		return nil, err
+	}
+
+	return &Metadata{
+		Title:        strings.TrimSpace(embed.Title),
+		ThumbnailURL: resolveURL(resp.Request.URL, embed.ThumbnailURL),
+		Duration:     time.Duration(embed.Duration) * time.Second,
+		Author:       strings.TrimSpace(embed.AuthorName),
+		SiteName:     strings.TrimSpace(embed.ProviderName),
+	}, nil
+}
+
+// get issues a GET request and returns the response only for 2xx statuses.
+// The caller must close the body.
+func (f *httpFetcher) get(ctx context.Context, target, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, ErrUnsupportedScheme
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,259 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testFetcher is NewFetcher with a plain client, since test servers listen
+// on loopback, which the default client refuses.
+func testFetcher(opts Options) Fetcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	return NewFetcher(opts)
+}
+
+func htmlHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestFetchOpenGraph(t *testing.T) {
+	server := newTestServer(t, htmlHandler(`<!doctype html><html><head>
+		<title>Fallback title</title>
+		<meta property="og:title" content="Sunset timelapse">
+		<meta content="Golden hour over the bay" property="og:description">
+		<meta property="og:image" content="/thumbs/sunset.jpg">
+		<meta property="og:video:duration" content="95">
+		<meta property="og:site_name" content="Example Video">
+	</head><body></body></html>`))
+
+	got, err := testFetcher(Options{}).Fetch(context.Background(), server.URL+"/watch/1")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	if got.Title != "Sunset timelapse" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	if got.Description != "Golden hour over the bay" {
+		t.Errorf("Description = %q", got.Description)
+	}
+	if want := server.URL + "/thumbs/sunset.jpg"; got.ThumbnailURL != want {
+		t.Errorf("ThumbnailURL = %q, want %q", got.ThumbnailURL, want)
+	}
+	if got.Duration != 95*time.Second {
+		t.Errorf("Duration = %v", got.Duration)
+	}
+	if got.SiteName != "Example Video" {
+		t.Errorf("SiteName = %q", got.SiteName)
+	}
+}
+
+func TestFetchTwitterAndTitleFallbacks(t *testing.T) {
+	server := newTestServer(t, htmlHandler(`<html><head>
+		<title>
+			Plain   page title
+		</title>
+		<meta name="twitter:image" content="https://cdn.example.com/card.png">
+		<meta name="description" content="Meta description">
+	</head></html>`))
+
+	got, err := testFetcher(Options{}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	if got.Title != "Plain page title" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	if got.ThumbnailURL != "https://cdn.example.com/card.png" {
+		t.Errorf("ThumbnailURL = %q", got.ThumbnailURL)
+	}
+	if got.Description != "Meta description" {
+		t.Errorf("Description = %q", got.Description)
+	}
+}
+
+func TestFetchJSONLDVideoObject(t *testing.T) {
+	server := newTestServer(t, htmlHandler(`<html><head>
+		<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+			{"@type":"WebPage","name":"Not the video"},
+			{"@type":["VideoObject"],"name":"Pasta in 10 minutes","description":"Quick dinner",
+			 "thumbnailUrl":["https://cdn.example.com/pasta.jpg"],"duration":"PT1H2M3S",
+			 "author":{"@type":"Person","name":"Chef Ana"}}
+		]}</script>
+	</head></html>`))
+
+	got, err := testFetcher(Options{}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	want := Metadata{
+		Title:        "Pasta in 10 minutes",
+		Description:  "Quick dinner",
+		ThumbnailURL: "https://cdn.example.com/pasta.jpg",
+		Duration:     time.Hour + 2*time.Minute + 3*time.Second,
+		Author:       "Chef Ana",
+	}
+	if *got != want {
+		t.Errorf("Fetch = %+v, want %+v", *got, want)
+	}
+}
+
+func TestFetchOEmbedDiscovery(t *testing.T) {
+	var server *httptest.Server
+	server = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oembed" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"title":"Reel title","author_name":"creator","thumbnail_url":"https://cdn.example.com/reel.jpg"}`)
+			return
+		}
+		htmlHandler(`<html><head><link rel="alternate" type="application/json+oembed" href="`+
+			server.URL+`/oembed?url=x"></head></html>`)(w, r)
+	})
+
+	got, err := testFetcher(Options{}).Fetch(context.Background(), server.URL+"/reel/1")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	if got.Title != "Reel title" || got.Author != "creator" || got.ThumbnailURL != "https://cdn.example.com/reel.jpg" {
+		t.Errorf("Fetch = %+v", *got)
+	}
+}
+
+func TestFetchCapsBodySize(t *testing.T) {
+	padding := strings.Repeat("<p>filler</p>", 1000)
+	server := newTestServer(t, htmlHandler(`<html><head><title>Early</title></head><body>`+
+		padding+`<meta property="og:title" content="Too late"></body></html>`))
+
+	got, err := testFetcher(Options{MaxBodyBytes: 512}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if got.Title != "Early" {
+		t.Errorf("Title = %q, want content past the cap to be ignored", got.Title)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	_, err := testFetcher(Options{Timeout: 50 * time.Millisecond}).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Fetch error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	if _, err := testFetcher(Options{}).Fetch(context.Background(), server.URL); !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("404 error = %v, want ErrUnexpectedStatus", err)
+	}
+	if _, err := testFetcher(Options{}).Fetch(context.Background(), "ftp://example.com/video"); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("ftp error = %v, want ErrUnsupportedScheme", err)
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT45S":     45 * time.Second,
+		"PT1M30S":   90 * time.Second,
+		"P1DT2H":    26 * time.Hour,
+		"PT0.5S":    500 * time.Millisecond,
+		"pt2m":      2 * time.Minute,
+		"":          0,
+		"PT":        0,
+		"1:30":      0,
+		"PT1H2M3SX": 0,
+	}
+	for input, want := range tests {
+		if got := parseISODuration(input); got != want {
+			t.Errorf("parseISODuration(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestFetchRefusesNonPublicAddresses(t *testing.T) {
+	server := newTestServer(t, htmlHandler(`<title>Internal</title>`))
+
+	if _, err := NewFetcher(Options{}).Fetch(context.Background(), server.URL); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("Fetch of a loopback server = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestFetchRefusesRedirectsToNonPublicAddresses(t *testing.T) {
+	internal := newTestServer(t, htmlHandler(`<title>Internal</title>`))
+	// The redirecting server stands in for a public site; only the hop to
+	// the internal address must be refused.
+	client := newSafeClient()
+	transport := client.Transport.(*http.Transport)
+	dial := transport.DialContext
+	public := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/admin", http.StatusFound)
+	})
+	publicAddr := strings.TrimPrefix(public.URL, "http://")
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == "public.example:80" {
+			return (&net.Dialer{}).DialContext(ctx, network, publicAddr)
+		}
+		return dial(ctx, network, addr)
+	}
+
+	_, err := NewFetcher(Options{Client: client}).Fetch(context.Background(), "http://public.example/")
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("Fetch through a redirect to loopback = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700::6810:84e5": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"255.255.255.255":      false,
+		"::1":                  false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"64:ff9b::a00:1":       false,
+	} {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// findVideoObject looks for a schema.org VideoObject in a JSON-LD block. The
+// block may hold a single object, an array of objects or an @graph.
+func findVideoObject(raw string) *Metadata {
+	var data interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &data); err != nil {
+		return nil
+	}
+	return searchVideoObject(data, 0)
+}
+
+func searchVideoObject(node interface{}, depth int) *Metadata {
+	if depth > 5 {
+		return nil
+	}
+
+	switch value := node.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if found := searchVideoObject(item, depth+1); found != nil {
+				return found
+			}
+		}
+	case map[string]interface{}:
+		if hasType(value["@type"], "VideoObject") {
+			return videoObjectMetadata(value)
+		}
+		if graph, ok := value["@graph"]; ok {
+			return searchVideoObject(graph, depth+1)
+		}
+	}
+	return nil
+}
+
+func hasType(node interface{}, want string) bool {
+	switch value := node.(type) {
+	case string:
+		return strings.EqualFold(value, want)
+	case []interface{}:
+		for _, item := range value {
+			if hasType(item, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func videoObjectMetadata(object map[string]interface{}) *Metadata {
+	result := &Metadata{
+		Title:        stringValue(object["name"]),
+		Description:  stringValue(object["description"]),
+		ThumbnailURL: urlValue(object["thumbnailUrl"]),
+		Duration:     parseISODuration(stringValue(object["duration"])),
+		Author:       nameValue(object["author"]),
+	}
+	if result.ThumbnailURL == "" {
+		result.ThumbnailURL = urlValue(object["thumbnail"])
+	}
+	if result.Author == "" {
+		result.Author = nameValue(object["creator"])
+	}
+	return result
+}
+
+func stringValue(node interface{}) string {
+	if s, ok := node.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+// urlValue accepts the shapes schema.org allows for image properties: a
+// string, an ImageObject with url/contentUrl, or an array of either.
+func urlValue(node interface{}) string {
+	switch value := node.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case []interface{}:
+		for _, item := range value {
+			if found := urlValue(item); found != "" {
+				return found
+			}
+		}
+	case map[string]interface{}:
+		if found := stringValue(value["url"]); found != "" {
+			return found
+		}
+		return stringValue(value["contentUrl"])
+	}
+	return ""
+}
+
+// nameValue accepts a plain name, a Person/Organization object or an array.
+func nameValue(node interface{}) string {
+	switch value := node.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case []interface{}:
+		for _, item := range value {
+			if found := nameValue(item); found != "" {
+				return found
+			}
+		}
+	case map[string]interface{}:
+		return stringValue(value["name"])
+	}
+	return ""
+}
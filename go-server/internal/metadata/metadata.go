@@ -0,0 +1,61 @@
+// Package metadata extracts preview information (title, description,
+// thumbnail, duration) from the pages behind saved links.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnsupportedScheme = errors.New("metadata: only http and https URLs are supported")
+	ErrUnexpectedStatus  = errors.New("metadata: unexpected response status")
+)
+
+// Metadata is the preview information found for a URL. Empty fields mean the
+// page did not expose that value.
+type Metadata struct {
+	Title        string
+	Description  string
+	ThumbnailURL string
+	Duration     time.Duration
+	Author       string
+	SiteName     string
+}
+
+// IsEmpty reports whether no preview information was found.
+func (m *Metadata) IsEmpty() bool {
+	return m.Title == "" && m.Description == "" && m.ThumbnailURL == "" &&
+		m.Duration == 0 && m.Author == "" && m.SiteName == ""
+}
+
+// merge fills the empty fields of m with values from other.
+func (m *Metadata) merge(other *Metadata) {
+	if other == nil {
+		return
+	}
+	if m.Title == "" {
+		m.Title = other.Title
+	}
+	if m.Description == "" {
+		m.Description = other.Description
+	}
+	if m.ThumbnailURL == "" {
+		m.ThumbnailURL = other.ThumbnailURL
+	}
+	if m.Duration == 0 {
+		m.Duration = other.Duration
+	}
+	if m.Author == "" {
+		m.Author = other.Author
+	}
+	if m.SiteName == "" {
+		m.SiteName = other.SiteName
+	}
+}
+
+// Fetcher loads metadata for a URL.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Metadata, error)
+}
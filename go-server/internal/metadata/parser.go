@@ -0,0 +1,168 @@
+package metadata
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// document holds the raw values collected from an HTML page before they are
+// resolved into Metadata.
+type document struct {
+	meta      map[string]string
+	title     string
+	jsonLD    []string
+	oembedURL string
+}
+
+func parseDocument(r io.Reader) *document {
+	doc := &document{meta: make(map[string]string)}
+	z := html.NewTokenizer(r)
+
+	var inTitle, inJSONLD bool
+	var text strings.Builder
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// io.EOF or a truncated body; either way keep what was parsed.
+			return doc
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			switch token.DataAtom {
+			case atom.Meta:
+				doc.addMeta(token)
+			case atom.Link:
+				doc.addLink(token)
+			case atom.Title:
+				inTitle = doc.title == ""
+				text.Reset()
+			case atom.Script:
+				inJSONLD = strings.EqualFold(strings.TrimSpace(attr(token, "type")), "application/ld+json")
+				text.Reset()
+			}
+
+		case html.TextToken:
+			if inTitle || inJSONLD {
+				text.Write(z.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				if inTitle {
+					doc.title = collapseSpace(text.String())
+					inTitle = false
+				}
+			case atom.Script:
+				if inJSONLD {
+					doc.jsonLD = append(doc.jsonLD, text.String())
+					inJSONLD = false
+				}
+			}
+		}
+	}
+}
+
+func (d *document) addMeta(token html.Token) {
+	key := attr(token, "property")
+	if key == "" {
+		key = attr(token, "name")
+	}
+	key = strings.ToLower(strings.TrimSpace(key))
+	content := strings.TrimSpace(attr(token, "content"))
+	if key == "" || content == "" {
+		return
+	}
+	if _, exists := d.meta[key]; !exists {
+		d.meta[key] = content
+	}
+}
+
+func (d *document) addLink(token html.Token) {
+	if d.oembedURL != "" {
+		return
+	}
+	rel := strings.ToLower(attr(token, "rel"))
+	linkType := strings.ToLower(attr(token, "type"))
+	if strings.Contains(rel, "alternate") && linkType == "application/json+oembed" {
+		d.oembedURL = strings.TrimSpace(attr(token, "href"))
+	}
+}
+
+// metadata resolves the collected values in priority order: Open Graph,
+// Twitter cards, JSON-LD VideoObject and finally plain HTML tags.
+func (d *document) metadata(base *url.URL) *Metadata {
+	result := &Metadata{
+		Title:        d.first("og:title", "twitter:title"),
+		Description:  d.first("og:description", "twitter:description"),
+		ThumbnailURL: d.first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src", "og:video:thumbnail"),
+		Duration:     parseSeconds(d.first("og:video:duration", "video:duration")),
+		SiteName:     d.first("og:site_name"),
+	}
+
+	for _, raw := range d.jsonLD {
+		if video := findVideoObject(raw); video != nil {
+			result.merge(video)
+			break
+		}
+	}
+
+	result.merge(&Metadata{
+		Title:       d.title,
+		Description: d.first("description"),
+		Author:      d.first("author"),
+	})
+
+	result.ThumbnailURL = resolveURL(base, result.ThumbnailURL)
+	return result
+}
+
+func (d *document) first(keys ...string) string {
+	for _, key := range keys {
+		if value := d.meta[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// resolveURL makes ref absolute against base. Only http(s) results are
+// returned; anything else (data:, javascript:, garbage) is dropped.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	} else if parsed.Scheme == "" && strings.HasPrefix(ref, "//") {
+		parsed.Scheme = "https"
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return parsed.String()
+}
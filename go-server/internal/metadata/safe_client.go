@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const maxRedirects = 10
+
+// ErrForbiddenAddress is returned for URLs that resolve to loopback,
+// private, link-local or other addresses that are not on the public
+// internet. Saved links come from users, so without this check they could
+// make the server fetch internal services or cloud metadata endpoints.
+var ErrForbiddenAddress = errors.New("metadata: refusing to connect to a non-public address")
+
+// nonPublicPrefixes are special-purpose ranges the netip predicates below
+// do not cover.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, may reach IPv4 internals
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// isPublicAddr reports whether addr is a unicast address on the public
+// internet.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// rejectNonPublic is a net.Dialer Control function. It runs after DNS
+// resolution, on the address actually dialed, so it also covers redirects
+// and hostnames that resolve to internal addresses.
+func rejectNonPublic(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// newSafeClient returns the default client: it dials public addresses
+// only, ignores proxy settings (the proxy would do the dialing instead)
+// and follows at most maxRedirects http(s) redirects.
+func newSafeClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   DefaultTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   rejectNonPublic,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("metadata: stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrUnsupportedScheme
+			}
+			return nil
+		},
+	}
+}
@@ -42,6 +42,8 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/ratelimit"
+)
+
+// RateLimit limits the requests to a route group with a token bucket per
+// rule, keyed by the group and the client IP or signed-in user. A "user"
+// rule must run after JWTAuthMiddleware and is skipped for anonymous
+// requests. Responses carry RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset for the tightest bucket; a request with no tokens left
+// gets 429 with Retry-After. If the store fails, requests are let through.
+func RateLimit(store ratelimit.Store, group string, rules []ratelimit.Rule) gin.HandlerFunc {
+	policies := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, fmt.Sprintf("%d;w=%d", rule.Limit.Requests, int(rule.Limit.Period.Seconds())))
+	}
+	policy := strings.Join(policies, ", ")
+
+	return func(c *gin.Context) {
+		buckets := make([]ratelimit.Bucket, 0, len(rules))
+		for _, rule := range rules {
+			subject, ok := rateLimitSubject(c, rule.Key)
+			if !ok {
+				continue
+			}
+			buckets = append(buckets, ratelimit.Bucket{Key: group + ":" + rule.Key + ":" + subject, Limit: rule.Limit})
+		}
+		if len(buckets) == 0 {
+			c.Next()
+			return
+		}
+
+		// Tokens are only spent when every bucket allows the request, so a
+		// client held back by one rule does not drain the others.
+		results, err := store.Take(c.Request.Context(), buckets...)
+		if err != nil {
+			log.Printf("Rate limit store failed, allowing request: %v", err)
+			c.Next()
+			return
+		}
+		tightest := &results[0]
+		for i := range results {
+			if tighter(&results[i], tightest) {
+				tightest = &results[i]
+			}
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(tightest.Limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(tightest.ResetAfter)))
+
+		if !tightest.Allowed {
+			seconds := ceilSeconds(tightest.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"message":     fmt.Sprintf("too many requests, try again in %d seconds", seconds),
+				"retry_after": seconds,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitSubject(c *gin.Context, key string) (string, bool) {
+	switch key {
+	case ratelimit.KeyIP:
+		return c.ClientIP(), true
+	case ratelimit.KeyUser:
+		userID, ok := c.Get("userID")
+		id, isUUID := userID.(uuid.UUID)
+		if !ok || !isUUID {
+			return "", false
+		}
+		return id.String(), true
+	}
+	return "", false
+}
+
+// tighter reports whether a leaves the client less room than b: a denial
+// before an allowance, then the longer wait or the fewer requests left.
+func tighter(a, b *ratelimit.Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+// ceilSeconds rounds d up to whole seconds, and at least one.
+func ceilSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/ratelimit"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// rateLimitedRouter serves GET /limited behind RateLimit. A request with
+// an X-User header is treated as signed in as that user.
+func rateLimitedRouter(store ratelimit.Store, rules ...ratelimit.Rule) *gin.Engine {
+	r := gin.New()
+	r.GET("/limited", func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set("userID", uuid.MustParse(user))
+		}
+	}, RateLimit(store, "test", rules), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func get(r http.Handler, ip, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = ip + ":1234"
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	r := rateLimitedRouter(ratelimit.NewMemoryStore(),
+		ratelimit.Rule{Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 2, Period: time.Minute}})
+
+	first := get(r, "203.0.113.7", "")
+	if first.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", first.Code)
+	}
+	if first.Header().Get("RateLimit-Limit") != "2" || first.Header().Get("RateLimit-Remaining") != "1" ||
+		first.Header().Get("RateLimit-Reset") != "30" || first.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Fatalf("first request headers: %v", first.Header())
+	}
+
+	get(r, "203.0.113.7", "")
+	denied := get(r, "203.0.113.7", "")
+	if denied.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", denied.Code)
+	}
+	if denied.Header().Get("Retry-After") != "30" || denied.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("denied request headers: %v", denied.Header())
+	}
+
+	if other := get(r, "198.51.100.1", ""); other.Code != http.StatusNoContent {
+		t.Fatalf("request from another IP: status %d", other.Code)
+	}
+}
+
+func TestRateLimitByUser(t *testing.T) {
+	r := rateLimitedRouter(ratelimit.NewMemoryStore(),
+		ratelimit.Rule{Key: ratelimit.KeyUser, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}})
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	if w := get(r, "203.0.113.7", alice); w.Code != http.StatusNoContent {
+		t.Fatalf("alice's first request: status %d", w.Code)
+	}
+	if w := get(r, "198.51.100.1", alice); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("alice's second request from another IP: status %d, want 429", w.Code)
+	}
+	if w := get(r, "203.0.113.7", bob); w.Code != http.StatusNoContent {
+		t.Fatalf("bob's request from alice's IP: status %d", w.Code)
+	}
+
+	for i := 0; i < 3; i++ {
+		w := get(r, "203.0.113.7", "")
+		if w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("anonymous request: status %d, headers %v; want no user limit", w.Code, w.Header())
+		}
+	}
+}
+
+func TestRateLimitReportsTightestRule(t *testing.T) {
+	r := rateLimitedRouter(ratelimit.NewMemoryStore(),
+		ratelimit.Rule{Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 100, Period: time.Minute}},
+		ratelimit.Rule{Key: ratelimit.KeyUser, Limit: ratelimit.Limit{Requests: 5, Period: time.Minute}})
+
+	w := get(r, "203.0.113.7", uuid.NewString())
+	if w.Header().Get("RateLimit-Limit") != "5" || w.Header().Get("RateLimit-Remaining") != "4" {
+		t.Fatalf("headers: %v; want the user rule's", w.Header())
+	}
+	if w.Header().Get("RateLimit-Policy") != "100;w=60, 5;w=60" {
+		t.Fatalf("RateLimit-Policy = %q", w.Header().Get("RateLimit-Policy"))
+	}
+}
+
+func TestRateLimitDeniedRequestsSpareOtherRules(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	r := rateLimitedRouter(store,
+		ratelimit.Rule{Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 1, Period: time.Hour}},
+		ratelimit.Rule{Key: ratelimit.KeyUser, Limit: ratelimit.Limit{Requests: 3, Period: time.Hour}})
+	user := uuid.NewString()
+
+	get(r, "203.0.113.7", user)
+	for i := 0; i < 5; i++ {
+		if w := get(r, "203.0.113.7", user); w.Code != http.StatusTooManyRequests {
+			t.Fatalf("request %d over the IP limit: status %d, want 429", i+2, w.Code)
+		}
+	}
+
+	// Only the first request counted against the user, who has two left.
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+		if w := get(r, ip, user); w.Code != http.StatusNoContent {
+			t.Fatalf("request from %s: status %d", ip, w.Code)
+		}
+	}
+	if w := get(r, "198.51.100.3", user); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("fourth request for the user: status %d, want 429", w.Code)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, ...ratelimit.Bucket) ([]ratelimit.Result, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestRateLimitLetsRequestsThroughWhenStoreFails(t *testing.T) {
+	r := rateLimitedRouter(failingStore{},
+		ratelimit.Rule{Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}})
+
+	for i := 0; i < 3; i++ {
+		if w := get(r, "203.0.113.7", ""); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d", i+1, w.Code)
+		}
+	}
+}
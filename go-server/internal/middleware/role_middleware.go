@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/rbac"
+)
+
+// RequireRole lets through users with one of roles. It must run after
+// JWTAuthMiddleware. The role is read from the access token, so a changed
+// role takes effect when the token is next refreshed.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return authorize(func(role string) bool {
+		for _, allowed := range roles {
+			if role == allowed {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RequirePermission lets through users whose role grants every one of
+// permissions in the rbac matrix. It must run after JWTAuthMiddleware.
+func RequirePermission(permissions ...rbac.Permission) gin.HandlerFunc {
+	return authorize(func(role string) bool {
+		for _, permission := range permissions {
+			if !rbac.Can(role, permission) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func authorize(allowed func(role string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := c.Get("role")
+		name, isString := role.(string)
+		if !ok || !isString {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "Unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		if !allowed(name) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "Forbidden",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/rbac"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+// adminRouter serves admin routes guarded the way the router guards them.
+func adminRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+	config.AppConfig = &config.Config{
+		JWT: config.JWTConfig{Secret: "test-secret", ExpiresIn: time.Hour},
+	}
+
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r := gin.New()
+	admin := r.Group("/admin", JWTAuthMiddleware())
+	admin.GET("/users/:id", RequirePermission(rbac.PermissionUsersRead), ok)
+	admin.PUT("/users/:id/role", RequirePermission(rbac.PermissionUsersManageRoles), ok)
+	admin.GET("/staff", RequireRole(constants.RoleAdmin, constants.RoleSuperAdmin), ok)
+	return r
+}
+
+func requestAs(t *testing.T, r http.Handler, method, path, role string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if role != "" {
+		token, err := utils.GenerateAccessToken(uuid.New(), "a@example.com", role, uuid.New())
+		if err != nil {
+			t.Fatalf("GenerateAccessToken: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestUserTokenCannotReachAdminRoutes(t *testing.T) {
+	r := adminRouter(t)
+	userPath := "/admin/users/" + uuid.NewString()
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, userPath},
+		{http.MethodPut, userPath + "/role"},
+		{http.MethodGet, "/admin/staff"},
+	} {
+		if code := requestAs(t, r, route.method, route.path, constants.RoleUser); code != http.StatusForbidden {
+			t.Errorf("%s %s as user: status %d, want 403", route.method, route.path, code)
+		}
+		if code := requestAs(t, r, route.method, route.path, ""); code != http.StatusUnauthorized {
+			t.Errorf("%s %s without a token: status %d, want 401", route.method, route.path, code)
+		}
+	}
+}
+
+func TestAdminRoutesFollowThePermissionMatrix(t *testing.T) {
+	r := adminRouter(t)
+	userPath := "/admin/users/" + uuid.NewString()
+
+	for _, tt := range []struct {
+		role, method, path string
+		want               int
+	}{
+		{constants.RoleAdmin, http.MethodGet, userPath, http.StatusNoContent},
+		{constants.RoleAdmin, http.MethodPut, userPath + "/role", http.StatusForbidden},
+		{constants.RoleAdmin, http.MethodGet, "/admin/staff", http.StatusNoContent},
+		{constants.RoleSuperAdmin, http.MethodGet, userPath, http.StatusNoContent},
+		{constants.RoleSuperAdmin, http.MethodPut, userPath + "/role", http.StatusNoContent},
+		{constants.RoleSuperAdmin, http.MethodGet, "/admin/staff", http.StatusNoContent},
+		{"root", http.MethodGet, userPath, http.StatusForbidden},
+	} {
+		if code := requestAs(t, r, tt.method, tt.path, tt.role); code != tt.want {
+			t.Errorf("%s %s as %s: status %d, want %d", tt.method, tt.path, tt.role, code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/service"
+)
+
+// ShareTokenMiddleware guards public collection routes. It resolves the
+// :token path parameter to an active share, checks the share password from
+// the X-Share-Password header, and stores the share as "collectionShare".
+// No user session is needed.
+func ShareTokenMiddleware(shareService service.CollectionShareService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		share, err := shareService.Resolve(c.Param("token"), c.GetHeader(constants.HeaderSharePassword))
+		if err != nil {
+			var throttled *service.ThrottledError
+			switch {
+			case errors.Is(err, service.ErrShareNotFound):
+				c.JSON(http.StatusNotFound, gin.H{
+					"message": err.Error(),
+				})
+			case errors.Is(err, service.ErrShareExpired):
+				c.JSON(http.StatusGone, gin.H{
+					"message": err.Error(),
+				})
+			case errors.Is(err, service.ErrSharePasswordRequired),
+				errors.Is(err, service.ErrSharePasswordInvalid):
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"message":           err.Error(),
+					"password_required": true,
+				})
+			case errors.As(err, &throttled):
+				seconds := ceilSeconds(throttled.RetryAfter)
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"message":     err.Error(),
+					"retry_after": seconds,
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"message": "Internal server error",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("collectionShare", share)
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/service"
+)
+
+// RequireVerifiedEmail rejects users who have not verified their email
+// address. It must run after JWTAuthMiddleware. The flag is read from the
+// database rather than the token, so it takes effect as soon as the email
+// is verified.
+func RequireVerifiedEmail(verificationService service.EmailVerificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("userID")
+		id, isUUID := userID.(uuid.UUID)
+		if !ok || !isUUID {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "Unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		verified, err := verificationService.IsVerified(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Internal server error",
+			})
+			c.Abort()
+			return
+		}
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message":                     "Please verify your email address first",
+				"email_verification_required": true,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Collection struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description *string   `gorm:"type:varchar(500)" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (c *Collection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CollectionItem places a link in a collection. Items are ordered by
+// Position, which is spaced out so an item can usually be moved between two
+// others by updating only its own row.
+type CollectionItem struct {
+	CollectionID uuid.UUID   `gorm:"type:uuid;primaryKey;index:idx_collection_items_position,priority:1" json:"collection_id"`
+	Collection   *Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	LinkID       uuid.UUID   `gorm:"type:uuid;primaryKey;index" json:"link_id"`
+	Link         *Link       `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE" json:"-"`
+	Position     int64       `gorm:"not null;index:idx_collection_items_position,priority:2" json:"position"`
+	AddedAt      time.Time   `gorm:"autoCreateTime" json:"added_at"`
+}
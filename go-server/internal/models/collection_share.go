@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// CollectionShare is a public, read-only link to a collection. Only the
+// SHA-256 of the share token is stored; the token itself is shown once,
+// when the share is created.
+type CollectionShare struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CollectionID uuid.UUID   `gorm:"type:uuid;not null;index" json:"collection_id"`
+	Collection   *Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	TokenHash    string      `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	PasswordHash *string     `gorm:"type:varchar(255)" json:"-"`
+	ExpiresAt    *time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time  `json:"revoked_at"`
+	ViewCount    int64       `gorm:"not null;default:0" json:"view_count"`
+	LastViewedAt *time.Time  `json:"last_viewed_at"`
+	CreatedAt    time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (s *CollectionShare) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+func (s *CollectionShare) SetPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	hash := string(hashedPassword)
+	s.PasswordHash = &hash
+	return nil
+}
+
+func (s *CollectionShare) ComparePassword(plainPassword string) bool {
+	if s.PasswordHash == nil {
+		return true
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(*s.PasswordHash), []byte(plainPassword))
+	return err == nil
+}
+
+func (s *CollectionShare) HasPassword() bool {
+	return s.PasswordHash != nil
+}
+
+// Expired reports whether the share has passed its expiry at now.
+func (s *CollectionShare) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Link struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:uniq_links_user_canonical_url,priority:1" json:"user_id"`
+	User            *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	URL             string    `gorm:"type:varchar(2048);not null" json:"url"`
+	CanonicalURL    *string   `gorm:"type:varchar(2048);uniqueIndex:uniq_links_user_canonical_url,priority:2" json:"canonical_url"`
+	Source          string    `gorm:"type:varchar(20);not null;default:'other'" json:"source"`
+	Title           *string   `gorm:"type:varchar(500)" json:"title"`
+	Description     *string   `gorm:"type:text" json:"description"`
+	AuthorName      *string   `gorm:"type:varchar(255)" json:"author_name"`
+	Notes           *string   `gorm:"type:text" json:"notes"`
+	Category        *string   `gorm:"type:varchar(50)" json:"category"`
+	ThumbnailURL    *string   `gorm:"type:varchar(2048)" json:"thumbnail_url"`
+	DurationSeconds *int      `gorm:"type:integer" json:"duration_seconds"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (l *Link) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LoginFailure is one failed password sign-in, counted against Key: an
+// account ("account:<email>") or a client IP ("ip:<address>").
+type LoginFailure struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Key       string    `gorm:"type:varchar(320);not null;index:idx_login_failures_key_created,priority:1" json:"key"`
+	CreatedAt time.Time `gorm:"not null;index:idx_login_failures_key_created,priority:2;index" json:"created_at"`
+}
+
+// LoginLockout bars password sign-ins for Key until LockedUntil.
+type LoginLockout struct {
+	Key         string    `gorm:"type:varchar(320);primaryKey" json:"key"`
+	LockedUntil time.Time `gorm:"not null;index" json:"locked_until"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MagicLinkToken is a single-use sign-in link sent to Email. The link is a
+// signed token carrying this ID; NonceHash is the SHA-256 of the cookie
+// that binds it to the requesting device. A row is kept for every request,
+// including unknown addresses that were never emailed, so throttling looks
+// the same for both.
+type MagicLinkToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Email     string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	NonceHash string     `gorm:"type:varchar(64);not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (t *MagicLinkToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Usable reports whether the link can still sign in at now.
+func (t *MagicLinkToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
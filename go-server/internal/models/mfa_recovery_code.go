@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MFARecoveryCode is a single-use code that stands in for an authenticator
+// app. Only the SHA-256 of the code is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:uniq_mfa_recovery_codes_user_code,priority:1" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;uniqueIndex:uniq_mfa_recovery_codes_user_code,priority:2" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (c *MFARecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
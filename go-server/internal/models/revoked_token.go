@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RevokedToken blocks an unexpired JWT by its jti claim.
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	RevokedAt time.Time `gorm:"autoCreateTime" json:"revoked_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session tracks one issued refresh token. Each refresh rotates the token:
+// the used row is marked RotatedAt and a new row joins the same FamilyID,
+// so a family is one sign-in on one device. Only the SHA-256 of the token is
+// stored.
+type Session struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	FamilyID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt time.Time  `gorm:"not null" json:"last_used_at"`
+	RotatedAt  *time.Time `json:"rotated_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a free-form label owned by a user. Names are stored normalized
+// (see service.NormalizeTagName) so each label exists once per user.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:uniq_tags_user_name,priority:1" json:"user_id"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:uniq_tags_user_name,priority:2" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+type LinkTag struct {
+	LinkID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"link_id"`
+	Link      *Link     `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE" json:"-"`
+	TagID     uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"tag_id"`
+	Tag       *Tag      `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -4,41 +4,97 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/constants"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
-	Password  string    `gorm:"type:varchar(255);not null" json:"-"`
-	Avatar    *string   `gorm:"type:varchar(500)" json:"avatar,omitempty"`
-	Role      string    `gorm:"type:varchar(50);default:'user'" json:"role"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID    uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name  string    `gorm:"type:varchar(100);not null" json:"name"`
+	Email string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	// Password is nil for accounts that only sign in through an OAuth
+	// provider.
+	Password      *string `gorm:"type:varchar(255)" json:"-"`
+	Avatar        *string `gorm:"type:varchar(500)" json:"avatar,omitempty"`
+	Role          string  `gorm:"type:varchar(50);default:'user'" json:"role"`
+	OAuthProvider *string `gorm:"column:oauth_provider;type:varchar(50);uniqueIndex:uniq_users_oauth,priority:1" json:"oauth_provider,omitempty"`
+	OAuthID       *string `gorm:"column:oauth_id;type:varchar(255);uniqueIndex:uniq_users_oauth,priority:2" json:"-"`
+	// EmailVerifiedAt is set once the user proves they own Email.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// TOTPSecret is the authenticator key, encrypted. It is set during
+	// enrollment; two-factor sign-in is on once TOTPEnabledAt is set too.
+	TOTPSecret    *string    `gorm:"column:totp_secret;type:varchar(255)" json:"-"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at"`
+	TOTPLastStep  int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	// Set the column default here too, so tokens issued right after
+	// sign-up carry the role.
+	if u.Role == "" {
+		u.Role = constants.RoleUser
+	}
 	return u.HashPassword()
 }
 
 func (u *User) HashPassword() error {
-	if u.Password == "" || len(u.Password) > 0 && u.Password[0] == '$' {
+	if u.Password == nil || *u.Password == "" || (*u.Password)[0] == '$' {
 		return nil
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*u.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	hash := string(hashedPassword)
+	u.Password = &hash
+	return nil
+}
+
+// SetPassword replaces the password with the bcrypt hash of password.
+func (u *User) SetPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	hash := string(hashedPassword)
+	u.Password = &hash
 	return nil
 }
 
 func (u *User) ComparePassword(plainPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(plainPassword))
+	if !u.HasPassword() {
+		CompareDummyPassword(plainPassword)
+		return false
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(*u.Password), []byte(plainPassword))
 	return err == nil
 }
+
+// dummyPasswordHash is a bcrypt hash at bcrypt.DefaultCost that no
+// password is expected to match.
+const dummyPasswordHash = "$2a$10$0dFKEz0RgJspwSqhpW9Dm.P0CIoJ98xpR9hcy3IVselcY6ULah62S"
+
+// CompareDummyPassword takes as long as checking a real password, so a
+// sign-in to an unknown email or a password-less account cannot be told
+// apart by its timing.
+func CompareDummyPassword(plainPassword string) {
+	_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(plainPassword))
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) MFAEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != nil
+}
+
+func (u *User) HasPassword() bool {
+	return u.Password != nil && *u.Password != ""
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an account at an external sign-in provider.
+// A user can have one identity per provider.
+type UserIdentity struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Provider    string    `gorm:"type:varchar(50);not null;uniqueIndex:uniq_user_identities_provider_subject,priority:1" json:"provider"`
+	Subject     string    `gorm:"type:varchar(255);not null;uniqueIndex:uniq_user_identities_provider_subject,priority:2" json:"-"`
+	Email       *string   `gorm:"type:varchar(255)" json:"email"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	LastLoginAt time.Time `gorm:"not null" json:"last_login_at"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.LastLoginAt.IsZero() {
+		i.LastLoginAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebAuthnCredential is a passkey a user registered for password-less
+// sign-in.
+type WebAuthnCredential struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	CredentialID []byte    `gorm:"type:bytea;not null;uniqueIndex" json:"-"`
+	// PublicKey is the COSE-encoded credential public key.
+	PublicKey []byte `gorm:"type:bytea;not null" json:"-"`
+	// SignCount is the authenticator's signature counter at the last
+	// sign-in; authenticators without a counter always report zero.
+	SignCount         int64     `gorm:"not null;default:0" json:"-"`
+	AAGUID            uuid.UUID `gorm:"column:aaguid;type:uuid" json:"aaguid"`
+	AttestationFormat string    `gorm:"type:varchar(32);not null" json:"attestation_format"`
+	// Transports is a comma-separated list of how the client reached the
+	// authenticator, such as "internal,hybrid".
+	Transports     string     `gorm:"type:varchar(255)" json:"transports"`
+	BackupEligible bool       `gorm:"not null;default:false" json:"backup_eligible"`
+	BackupState    bool       `gorm:"not null;default:false" json:"backup_state"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+func (c *WebAuthnCredential) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const appleIssuer = "https://appleid.apple.com"
+
+// AppleConfig is the OIDCConfig for Sign in with Apple. Apple posts the
+// callback as a form and only does so for the email scope.
+func AppleConfig(client Client) OIDCConfig {
+	if len(client.Scopes) == 0 {
+		client.Scopes = []string{"openid", "email", "name"}
+	}
+	return OIDCConfig{
+		Name:   "apple",
+		Issuer: appleIssuer,
+		Client: client,
+		Endpoints: Endpoints{
+			AuthURL:  appleIssuer + "/auth/authorize",
+			TokenURL: appleIssuer + "/auth/token",
+		},
+		JWKSURL:    appleIssuer + "/auth/keys",
+		AuthParams: url.Values{"response_mode": {"form_post"}},
+	}
+}
+
+// AppleClientSecret returns a Client.Secret that signs the short-lived
+// ES256 JWT Apple accepts as a client secret, using the .p8 key from the
+// Apple developer account.
+func AppleClientSecret(teamID, clientID, keyID string, privateKeyPEM []byte) (func() (string, error), error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("oauth: apple private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("oauth: apple private key is not an ECDSA key")
+	}
+
+	return func() (string, error) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			Issuer:    teamID,
+			Subject:   clientID,
+			Audience:  jwt.ClaimStrings{appleIssuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		})
+		token.Header["kid"] = keyID
+		return token.SignedString(key)
+	}, nil
+}
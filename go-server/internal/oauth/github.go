@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GitHubProvider signs users in with GitHub, which speaks plain OAuth 2.0:
+// the user is read from the REST API with the access token.
+type GitHubProvider struct {
+	client    Client
+	endpoints Endpoints
+	apiURL    string
+}
+
+// NewGitHubProvider uses github.com unless apiURL and endpoints are given,
+// as they are for GitHub Enterprise and tests.
+func NewGitHubProvider(client Client, endpoints Endpoints, apiURL string) *GitHubProvider {
+	if endpoints.AuthURL == "" {
+		endpoints = Endpoints{
+			AuthURL:  "https://github.com/login/oauth/authorize",
+			TokenURL: "https://github.com/login/oauth/access_token",
+		}
+	}
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	if len(client.Scopes) == 0 {
+		client.Scopes = []string{"read:user", "user:email"}
+	}
+	return &GitHubProvider{client: client, endpoints: endpoints, apiURL: strings.TrimRight(apiURL, "/")}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) AuthCodeURL(req *AuthRequest) string {
+	return p.client.authCodeURL(p.endpoints, req, nil)
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*Identity, error) {
+	token, err := p.client.exchange(ctx, p.endpoints, code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access_token in response", ErrExchangeFailed)
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token.AccessToken, "/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: no user id", ErrExchangeFailed)
+	}
+
+	// The profile email is optional and unverified; the emails endpoint
+	// says which address is primary and verified.
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, token.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     strings.TrimSpace(user.Name),
+		Picture:  user.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = strings.ToLower(strings.TrimSpace(email.Email))
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+func (p *GitHubProvider) get(ctx context.Context, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	status, err := p.client.do(req, out)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrExchangeFailed, path, err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: %s: unexpected status %d", ErrExchangeFailed, path, status)
+	}
+	return nil
+}
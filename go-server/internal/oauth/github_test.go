@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubProviderUsesPrimaryVerifiedEmail(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("code") != "good-code" || r.Header.Get("Accept") != "application/json" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 583231, "login": "octocat", "name": "", "avatar_url": "https://avatars.example.com/u/583231",
+		})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "Octo@Example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := NewGitHubProvider(
+		Client{ID: "gh-client", Secret: StaticSecret("gh-secret"), RedirectURL: testRedirectURL},
+		Endpoints{AuthURL: server.URL + "/login/oauth/authorize", TokenURL: server.URL + "/login/oauth/access_token"},
+		server.URL,
+	)
+
+	req, _ := NewAuthRequest()
+	identity, err := provider.Exchange(context.Background(), "good-code", req)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{
+		Provider:      "github",
+		Subject:       "583231",
+		Email:         "octo@example.com",
+		EmailVerified: true,
+		Name:          "octocat",
+		Picture:       "https://avatars.example.com/u/583231",
+	}
+	if *identity != want {
+		t.Fatalf("identity = %+v, want %+v", *identity, want)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "bad-code", req); err == nil {
+		t.Fatal("Exchange accepted a rejected code")
+	}
+}
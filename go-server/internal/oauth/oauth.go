@@ -0,0 +1,188 @@
+// Package oauth implements the authorization code flow with PKCE for
+// social sign-in providers and normalises what they say about the user.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const maxResponseBytes = 1 << 20
+
+var (
+	ErrExchangeFailed = errors.New("oauth: code exchange failed")
+	ErrNonceMismatch  = errors.New("oauth: nonce mismatch")
+)
+
+// Identity is a provider account, normalised across providers.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// AuthRequest holds the per-attempt secrets of one sign-in.
+type AuthRequest struct {
+	State        string
+	CodeVerifier string
+	Nonce        string
+}
+
+// NewAuthRequest generates a fresh state, PKCE verifier and nonce.
+func NewAuthRequest() (*AuthRequest, error) {
+	values := make([]string, 3)
+	for i := range values {
+		value, err := randomString(32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return &AuthRequest{State: values[0], CodeVerifier: values[1], Nonce: values[2]}, nil
+}
+
+// CodeChallenge is the S256 PKCE challenge for the request's verifier.
+func (r *AuthRequest) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(r.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Endpoints are a provider's OAuth 2.0 URLs.
+type Endpoints struct {
+	AuthURL  string
+	TokenURL string
+}
+
+// Client is the app's registration with a provider.
+type Client struct {
+	ID string
+	// Secret returns the client secret. Apple wants a freshly signed JWT
+	// rather than a fixed string, hence a function.
+	Secret      func() (string, error)
+	RedirectURL string
+	Scopes      []string
+	HTTPClient  *http.Client
+}
+
+// StaticSecret returns a Client.Secret for a fixed client secret.
+func StaticSecret(secret string) func() (string, error) {
+	return func() (string, error) { return secret, nil }
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+func (c *Client) authCodeURL(endpoints Endpoints, req *AuthRequest, extra url.Values) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.ID},
+		"redirect_uri":          {c.RedirectURL},
+		"state":                 {req.State},
+		"code_challenge":        {req.CodeChallenge()},
+		"code_challenge_method": {"S256"},
+	}
+	if len(c.Scopes) > 0 {
+		params.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for key, values := range extra {
+		params[key] = values
+	}
+
+	separator := "?"
+	if strings.Contains(endpoints.AuthURL, "?") {
+		separator = "&"
+	}
+	return endpoints.AuthURL + separator + params.Encode()
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c *Client) exchange(ctx context.Context, endpoints Endpoints, code, codeVerifier string) (*tokenResponse, error) {
+	secret := ""
+	if c.Secret != nil {
+		var err error
+		if secret, err = c.Secret(); err != nil {
+			return nil, err
+		}
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"client_id":     {c.ID},
+		"code_verifier": {codeVerifier},
+	}
+	if secret != "" {
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	status, err := c.do(req, &token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrExchangeFailed, status)
+	}
+	return &token, nil
+}
+
+// do sends req and decodes a JSON body into out, whatever the status.
+func (c *Client) do(req *http.Request, out interface{}) (int, error) {
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
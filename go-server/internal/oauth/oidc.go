@@ -0,0 +1,145 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/video-mobile-app/go-server/internal/idtoken"
+)
+
+// OIDCProvider signs users in with any OpenID Connect issuer. The user is
+// described by the ID token from the token response, whose signature,
+// issuer, audience and nonce are all checked.
+type OIDCProvider struct {
+	name      string
+	client    Client
+	endpoints Endpoints
+	verifier  *idtoken.Verifier
+	extra     url.Values
+}
+
+type OIDCConfig struct {
+	Name   string
+	Issuer string
+	Client Client
+	// Endpoints and JWKSURL can be left empty when Discover filled them.
+	Endpoints Endpoints
+	JWKSURL   string
+	// Issuers overrides the accepted iss values, for issuers like Google
+	// that use more than one spelling.
+	Issuers []string
+	// AuthParams are added to the authorization URL, such as Apple's
+	// response_mode=form_post.
+	AuthParams url.Values
+}
+
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	issuers := cfg.Issuers
+	if len(issuers) == 0 {
+		issuers = []string{cfg.Issuer}
+	}
+	if len(cfg.Client.Scopes) == 0 {
+		cfg.Client.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &OIDCProvider{
+		name:      cfg.Name,
+		client:    cfg.Client,
+		endpoints: cfg.Endpoints,
+		verifier: idtoken.NewVerifier(idtoken.Config{
+			Issuers:   issuers,
+			Audiences: []string{cfg.Client.ID},
+			Keys:      idtoken.NewRemoteKeySet(cfg.JWKSURL, cfg.Client.HTTPClient),
+		}),
+		extra: cfg.AuthParams,
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) AuthCodeURL(req *AuthRequest) string {
+	extra := url.Values{"nonce": {req.Nonce}}
+	for key, values := range p.extra {
+		extra[key] = values
+	}
+	return p.client.authCodeURL(p.endpoints, req, extra)
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*Identity, error) {
+	token, err := p.client.exchange(ctx, p.endpoints, code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrExchangeFailed)
+	}
+
+	claims, err := p.verifier.Verify(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(req.Nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+
+	return &Identity{
+		Provider:      p.name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: claims.EmailVerified,
+		Name:          strings.TrimSpace(claims.Name),
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// Discover reads an issuer's OpenID configuration and fills in cfg's
+// endpoints and key URL.
+func Discover(ctx context.Context, cfg *OIDCConfig) error {
+	wellKnown := strings.TrimRight(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	status, err := cfg.Client.do(req, &doc)
+	if err != nil {
+		return fmt.Errorf("oauth: discover %s: %w", cfg.Issuer, err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("oauth: discover %s: unexpected status %d", cfg.Issuer, status)
+	}
+	if doc.Issuer != cfg.Issuer {
+		return fmt.Errorf("oauth: discover %s: document is for issuer %q", cfg.Issuer, doc.Issuer)
+	}
+
+	cfg.Endpoints = Endpoints{AuthURL: doc.AuthorizationEndpoint, TokenURL: doc.TokenEndpoint}
+	cfg.JWKSURL = doc.JWKSURI
+	return nil
+}
+
+// GoogleConfig is the OIDCConfig for Google's web sign-in.
+func GoogleConfig(client Client) OIDCConfig {
+	return OIDCConfig{
+		Name:   "google",
+		Issuer: idtoken.GoogleIssuer,
+		Client: client,
+		Endpoints: Endpoints{
+			AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+			TokenURL: "https://oauth2.googleapis.com/token",
+		},
+		JWKSURL: idtoken.GoogleJWKSURL,
+		Issuers: idtoken.GoogleIssuers,
+	}
+}
@@ -0,0 +1,233 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "video-vault"
+	testRedirectURL = "http://localhost:8000/api/auth/oauth/mock/callback"
+)
+
+// mockIssuer is a minimal OpenID Connect provider: discovery, JWKS, an
+// authorization endpoint that hands out codes, and a token endpoint that
+// enforces PKCE.
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+	// wrongNonce makes the issuer sign ID tokens with a different nonce.
+	wrongNonce bool
+}
+
+type mockGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	m := &mockIssuer{key: key, codes: make(map[string]mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "mock-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		code := "code-" + q.Get("state")
+		m.mu.Lock()
+		m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+		m.mu.Unlock()
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code="+code+"&state="+q.Get("state"), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		m.mu.Lock()
+		grant, ok := m.codes[r.PostForm.Get("code")]
+		delete(m.codes, r.PostForm.Get("code"))
+		m.mu.Unlock()
+
+		verifier := (&AuthRequest{CodeVerifier: r.PostForm.Get("code_verifier")}).CodeChallenge()
+		if !ok || verifier != grant.challenge || r.PostForm.Get("client_secret") != "shh" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		nonce := grant.nonce
+		if m.wrongNonce {
+			nonce = "something-else"
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            m.server.URL,
+			"aud":            testClientID,
+			"sub":            "mock-user-1",
+			"email":          "Jane@Example.com",
+			"email_verified": "true",
+			"name":           "Jane Doe",
+			"nonce":          nonce,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "mock-key"
+		idToken, _ := token.SignedString(key)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "mock-access-token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize follows the authorization URL the way a browser would and
+// returns the code and state sent back to the redirect URL.
+func (m *mockIssuer) authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newMockProvider(t *testing.T, m *mockIssuer) *OIDCProvider {
+	t.Helper()
+	cfg := OIDCConfig{
+		Name:   "mock",
+		Issuer: m.server.URL,
+		Client: Client{
+			ID:          testClientID,
+			Secret:      StaticSecret("shh"),
+			RedirectURL: testRedirectURL,
+		},
+	}
+	if err := Discover(context.Background(), &cfg); err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	return NewOIDCProvider(cfg)
+}
+
+func TestOIDCProviderSignIn(t *testing.T) {
+	m := newMockIssuer(t)
+	provider := newMockProvider(t, m)
+
+	req, err := NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest: %v", err)
+	}
+
+	authURL := provider.AuthCodeURL(req)
+	parsed, _ := url.Parse(authURL)
+	q := parsed.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") != req.CodeChallenge() {
+		t.Fatalf("authorization URL lacks PKCE: %s", authURL)
+	}
+	if q.Get("nonce") != req.Nonce || q.Get("redirect_uri") != testRedirectURL || q.Get("scope") != "openid email profile" {
+		t.Fatalf("unexpected authorization URL: %s", authURL)
+	}
+
+	code, state := m.authorize(t, authURL)
+	if state != req.State {
+		t.Fatalf("state = %q, want %q", state, req.State)
+	}
+
+	identity, err := provider.Exchange(context.Background(), code, req)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{
+		Provider:      "mock",
+		Subject:       "mock-user-1",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+	}
+	if *identity != want {
+		t.Fatalf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCProviderRejectsWrongVerifier(t *testing.T) {
+	m := newMockIssuer(t)
+	provider := newMockProvider(t, m)
+
+	req, _ := NewAuthRequest()
+	code, _ := m.authorize(t, provider.AuthCodeURL(req))
+
+	stolen := *req
+	stolen.CodeVerifier = "not-the-verifier"
+	if _, err := provider.Exchange(context.Background(), code, &stolen); !errors.Is(err, ErrExchangeFailed) {
+		t.Fatalf("Exchange error = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestOIDCProviderRejectsNonceMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	m.wrongNonce = true
+	provider := newMockProvider(t, m)
+
+	req, _ := NewAuthRequest()
+	code, _ := m.authorize(t, provider.AuthCodeURL(req))
+
+	if _, err := provider.Exchange(context.Background(), code, req); !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("Exchange error = %v, want ErrNonceMismatch", err)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	cfg := OIDCConfig{Issuer: m.server.URL + "/other"}
+	if err := Discover(context.Background(), &cfg); err == nil {
+		t.Fatal("Discover accepted a document for another issuer")
+	}
+}
@@ -0,0 +1,86 @@
+// Package pagination implements keyset pagination over (created_at, id)
+// with opaque cursors.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a page. The next page starts strictly after
+// it in (created_at DESC, id DESC) order, or (rank DESC, created_at DESC,
+// id DESC) for relevance-ordered results. Manually ordered lists keep their
+// position in Rank. MatchType records which kind of search produced the
+// page, so later pages keep using it.
+type Cursor struct {
+	MatchType string
+	Rank      float64
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type cursorPayload struct {
+	MatchType string    `json:"m,omitempty"`
+	Rank      float64   `json:"r,omitempty"`
+	CreatedAt string    `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque string form handed to clients.
+func (c Cursor) Encode() string {
+	payload, _ := json.Marshal(cursorPayload{
+		MatchType: c.MatchType,
+		Rank:      c.Rank,
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        c.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// Decode parses a cursor produced by Encode. An empty string is not a
+// cursor and yields nil without error.
+func Decode(encoded string) (*Cursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{MatchType: payload.MatchType, Rank: payload.Rank, CreatedAt: createdAt, ID: payload.ID}, nil
+}
+
+// Limit clamps a requested page size to [1, MaxLimit], using DefaultLimit
+// when none was requested.
+func Limit(requested int) int {
+	if requested <= 0 {
+		return DefaultLimit
+	}
+	if requested > MaxLimit {
+		return MaxLimit
+	}
+	return requested
+}
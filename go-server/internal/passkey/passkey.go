@@ -0,0 +1,262 @@
+// Package passkey runs the WebAuthn registration and sign-in ceremonies
+// for passkeys. It accepts "none" and "packed" attestation and always
+// requires user verification, so a passkey stands in for both the password
+// and the second factor.
+package passkey
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Attestation formats a registration may use.
+const (
+	AttestationNone   = "none"
+	AttestationPacked = "packed"
+)
+
+var (
+	ErrInvalidResponse        = errors.New("passkey: invalid authenticator response")
+	ErrUnsupportedAttestation = errors.New("passkey: unsupported attestation format")
+	ErrUnknownCredential      = errors.New("passkey: unknown credential")
+	// ErrSignCount means the authenticator's signature counter did not go
+	// up, a sign the credential was cloned.
+	ErrSignCount = errors.New("passkey: signature counter did not increase")
+)
+
+type Config struct {
+	// RPID is the domain passkeys are bound to, such as "example.com".
+	RPID   string
+	RPName string
+	// Origins are the web origins, and android:apk-key-hash: origins of
+	// the app, that ceremonies may come from.
+	Origins []string
+	// Timeout is how long the client is told to wait for the user.
+	Timeout time.Duration
+}
+
+// User is an account taking part in a ceremony.
+type User struct {
+	// Handle is the opaque user ID stored on the authenticator.
+	Handle      []byte
+	Name        string
+	DisplayName string
+	Credentials []Credential
+}
+
+// Credential is a registered passkey.
+type Credential struct {
+	ID                []byte
+	PublicKey         []byte
+	SignCount         uint32
+	AAGUID            []byte
+	AttestationFormat string
+	Transports        []string
+	BackupEligible    bool
+	BackupState       bool
+}
+
+// Session is the state a ceremony carries from its options to its
+// response. The caller keeps it, and must use it only once.
+type Session struct {
+	Challenge string `json:"challenge"`
+	// UserHandle is set for registrations; sign-ins find the user from
+	// the credential.
+	UserHandle []byte `json:"user_handle,omitempty"`
+}
+
+type RelyingParty struct {
+	webauthn *webauthn.WebAuthn
+}
+
+func New(cfg Config) (*RelyingParty, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPName,
+		RPOrigins:     cfg.Origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Timeout: cfg.Timeout, TimeoutUVD: cfg.Timeout},
+			Registration: webauthn.TimeoutConfig{Timeout: cfg.Timeout, TimeoutUVD: cfg.Timeout},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RelyingParty{webauthn: wa}, nil
+}
+
+// BeginRegistration returns the options for navigator.credentials.create.
+// The user's existing passkeys are excluded so an authenticator is not
+// registered twice.
+func (rp *RelyingParty) BeginRegistration(user *User) (*protocol.PublicKeyCredentialCreationOptions, *Session, error) {
+	exclude := make([]protocol.CredentialDescriptor, 0, len(user.Credentials))
+	for _, credential := range user.Credentials {
+		exclude = append(exclude, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: credential.ID,
+		})
+	}
+
+	creation, session, err := rp.webauthn.BeginRegistration(
+		webauthnUser{user},
+		webauthn.WithExclusions(exclude),
+		webauthn.WithConveyancePreference(protocol.PreferNoAttestation),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &creation.Response, &Session{Challenge: session.Challenge, UserHandle: session.UserID}, nil
+}
+
+// FinishRegistration checks the JSON-encoded PublicKeyCredential the
+// client got from navigator.credentials.create and returns the new
+// passkey.
+func (rp *RelyingParty) FinishRegistration(user *User, session *Session, response []byte) (*Credential, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, invalidResponse(err)
+	}
+
+	format := parsed.Response.AttestationObject.Format
+	if format != AttestationNone && format != AttestationPacked {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAttestation, format)
+	}
+
+	credential, err := rp.webauthn.CreateCredential(webauthnUser{user}, webauthn.SessionData{
+		Challenge:        session.Challenge,
+		UserID:           session.UserHandle,
+		UserVerification: protocol.VerificationRequired,
+	}, parsed)
+	if err != nil {
+		return nil, invalidResponse(err)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return &Credential{
+		ID:                credential.ID,
+		PublicKey:         credential.PublicKey,
+		SignCount:         credential.Authenticator.SignCount,
+		AAGUID:            credential.Authenticator.AAGUID,
+		AttestationFormat: format,
+		Transports:        transports,
+		BackupEligible:    credential.Flags.BackupEligible,
+		BackupState:       credential.Flags.BackupState,
+	}, nil
+}
+
+// BeginLogin returns the options for navigator.credentials.get. No
+// credentials are listed: the authenticator offers the passkeys it holds
+// for this site.
+func (rp *RelyingParty) BeginLogin() (*protocol.PublicKeyCredentialRequestOptions, *Session, error) {
+	assertion, session, err := rp.webauthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &assertion.Response, &Session{Challenge: session.Challenge}, nil
+}
+
+// FinishLogin checks the JSON-encoded PublicKeyCredential the client got
+// from navigator.credentials.get. lookup finds the account that owns the
+// credential, returning ErrUnknownCredential when there is none. The
+// returned credential carries the new signature counter and backup state
+// to store.
+func (rp *RelyingParty) FinishLogin(session *Session, response []byte, lookup func(credentialID, userHandle []byte) (*User, error)) (*User, *Credential, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, nil, invalidResponse(err)
+	}
+
+	var user *User
+	var lookupErr error
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		user, lookupErr = lookup(rawID, userHandle)
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		return webauthnUser{user}, nil
+	}
+
+	verified, err := rp.webauthn.ValidateDiscoverableLogin(handler, webauthn.SessionData{
+		Challenge:        session.Challenge,
+		UserVerification: protocol.VerificationRequired,
+	}, parsed)
+	if lookupErr != nil {
+		return nil, nil, lookupErr
+	}
+	if err != nil {
+		return nil, nil, invalidResponse(err)
+	}
+	if verified.Authenticator.CloneWarning {
+		return nil, nil, ErrSignCount
+	}
+
+	for _, credential := range user.Credentials {
+		if bytes.Equal(credential.ID, verified.ID) {
+			credential.SignCount = verified.Authenticator.SignCount
+			credential.BackupState = verified.Flags.BackupState
+			return user, &credential, nil
+		}
+	}
+	return nil, nil, ErrUnknownCredential
+}
+
+func invalidResponse(err error) error {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidResponse, protocolErr.Details, protocolErr.DevInfo)
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+}
+
+// webauthnUser adapts User to the webauthn library.
+type webauthnUser struct {
+	*User
+}
+
+func (u webauthnUser) WebAuthnID() []byte {
+	return u.Handle
+}
+
+func (u webauthnUser) WebAuthnName() string {
+	return u.Name
+}
+
+func (u webauthnUser) WebAuthnDisplayName() string {
+	return u.DisplayName
+}
+
+func (u webauthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.Credentials))
+	for _, credential := range u.Credentials {
+		credentials = append(credentials, webauthn.Credential{
+			ID:              credential.ID,
+			PublicKey:       credential.PublicKey,
+			AttestationType: credential.AttestationFormat,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    credential.AAGUID,
+				SignCount: credential.SignCount,
+			},
+		})
+	}
+	return credentials
+}
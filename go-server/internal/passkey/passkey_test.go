@@ -0,0 +1,496 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:8000"
+
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+var b64 = base64.RawURLEncoding
+
+// softAuthenticator is a software passkey: one P-256 credential that signs
+// whatever it is asked to.
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	aaguid       []byte
+	signCount    uint32
+	origin       string
+	flags        byte
+	noCounter    bool
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	aaguid := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(aaguid); err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{
+		t:            t,
+		key:          key,
+		credentialID: credentialID,
+		aaguid:       aaguid,
+		origin:       testOrigin,
+		flags:        flagUserPresent | flagUserVerified,
+	}
+}
+
+func (a *softAuthenticator) clientData(ceremony, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	flags := a.flags
+	if attested {
+		flags |= flagAttested
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !attested {
+		return data
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	data = append(data, a.aaguid...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	return append(data, publicKey...)
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, authData, clientData []byte) []byte {
+	t.Helper()
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+// create answers registration options the way navigator.credentials.create
+// would. attStmt builds the attestation statement for format.
+func (a *softAuthenticator) create(user *User, challenge, format string, attStmt func(authData, clientData []byte) map[string]interface{}) []byte {
+	a.userHandle = user.Handle
+	clientData := a.clientData("webauthn.create", challenge)
+	authData := a.authData(true)
+
+	stmt := map[string]interface{}{}
+	if attStmt != nil {
+		stmt = attStmt(authData, clientData)
+	}
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      format,
+		"attStmt":  stmt,
+		"authData": authData,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return a.credential(map[string]interface{}{
+		"clientDataJSON":    b64.EncodeToString(clientData),
+		"attestationObject": b64.EncodeToString(attestationObject),
+		"transports":        []string{"internal", "hybrid"},
+	})
+}
+
+// get answers sign-in options the way navigator.credentials.get would.
+func (a *softAuthenticator) get(challenge string) []byte {
+	if !a.noCounter {
+		a.signCount++
+	}
+	clientData := a.clientData("webauthn.get", challenge)
+	authData := a.authData(false)
+
+	return a.credential(map[string]interface{}{
+		"clientDataJSON":    b64.EncodeToString(clientData),
+		"authenticatorData": b64.EncodeToString(authData),
+		"signature":         b64.EncodeToString(sign(a.t, a.key, authData, clientData)),
+		"userHandle":        b64.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) credential(response map[string]interface{}) []byte {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":       b64.EncodeToString(a.credentialID),
+		"rawId":    b64.EncodeToString(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return body
+}
+
+func (a *softAuthenticator) packedSelf(authData, clientData []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"alg": int64(webauthncose.AlgES256),
+		"sig": sign(a.t, a.key, authData, clientData),
+	}
+}
+
+// packedFull signs with a separate attestation key whose certificate
+// names the authenticator model.
+func (a *softAuthenticator) packedFull(authData, clientData []byte) map[string]interface{} {
+	attestationKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			Country:            []string{"US"},
+			Organization:       []string{"Test Vendor"},
+			OrganizationalUnit: []string{"Authenticator Attestation"},
+			CommonName:         "Test Authenticator",
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &attestationKey.PublicKey, attestationKey)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return map[string]interface{}{
+		"alg": int64(webauthncose.AlgES256),
+		"sig": sign(a.t, attestationKey, authData, clientData),
+		"x5c": []interface{}{cert},
+	}
+}
+
+func newTestRelyingParty(t *testing.T) *RelyingParty {
+	t.Helper()
+	rp, err := New(Config{
+		RPID:    testRPID,
+		RPName:  "Video Vault",
+		Origins: []string{testOrigin},
+		Timeout: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return rp
+}
+
+func testUser() *User {
+	return &User{
+		Handle:      []byte("0123456789abcdef"),
+		Name:        "jane@example.com",
+		DisplayName: "Jane",
+	}
+}
+
+// register runs a registration ceremony and stores the passkey on user.
+func register(t *testing.T, rp *RelyingParty, user *User, authenticator *softAuthenticator, format string, attStmt func(authData, clientData []byte) map[string]interface{}) (*Credential, error) {
+	t.Helper()
+	options, session, err := rp.BeginRegistration(user)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	response := authenticator.create(user, options.Challenge.String(), format, attStmt)
+	credential, err := rp.FinishRegistration(user, session, response)
+	if err == nil {
+		user.Credentials = append(user.Credentials, *credential)
+	}
+	return credential, err
+}
+
+func lookupIn(user *User) func(credentialID, userHandle []byte) (*User, error) {
+	return func(credentialID, userHandle []byte) (*User, error) {
+		for _, credential := range user.Credentials {
+			if string(credential.ID) == string(credentialID) && string(userHandle) == string(user.Handle) {
+				return user, nil
+			}
+		}
+		return nil, ErrUnknownCredential
+	}
+}
+
+func login(t *testing.T, rp *RelyingParty, user *User, authenticator *softAuthenticator) (*Credential, error) {
+	t.Helper()
+	options, session, err := rp.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	if options.UserVerification != "required" || len(options.AllowedCredentials) != 0 {
+		t.Fatalf("login options = %+v, want discoverable with user verification", options)
+	}
+	owner, credential, err := rp.FinishLogin(session, authenticator.get(options.Challenge.String()), lookupIn(user))
+	if err == nil && owner != user {
+		t.Fatalf("FinishLogin returned another user")
+	}
+	return credential, err
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	formats := map[string]func(a *softAuthenticator) func(authData, clientData []byte) map[string]interface{}{
+		"none": func(a *softAuthenticator) func(authData, clientData []byte) map[string]interface{} { return nil },
+		"packed self": func(a *softAuthenticator) func(authData, clientData []byte) map[string]interface{} {
+			return a.packedSelf
+		},
+		"packed full": func(a *softAuthenticator) func(authData, clientData []byte) map[string]interface{} {
+			return a.packedFull
+		},
+	}
+
+	for name, attStmt := range formats {
+		t.Run(name, func(t *testing.T) {
+			rp := newTestRelyingParty(t)
+			user := testUser()
+			authenticator := newSoftAuthenticator(t)
+
+			format := AttestationPacked
+			if name == "none" {
+				format = AttestationNone
+			}
+			credential, err := register(t, rp, user, authenticator, format, attStmt(authenticator))
+			if err != nil {
+				t.Fatalf("FinishRegistration: %v", err)
+			}
+			if string(credential.ID) != string(authenticator.credentialID) || credential.AttestationFormat != format {
+				t.Fatalf("credential = %+v", credential)
+			}
+			if len(credential.Transports) != 2 || credential.Transports[0] != "internal" {
+				t.Fatalf("transports = %v", credential.Transports)
+			}
+
+			for i := 1; i <= 2; i++ {
+				used, err := login(t, rp, user, authenticator)
+				if err != nil {
+					t.Fatalf("FinishLogin #%d: %v", i, err)
+				}
+				if used.SignCount != uint32(i) {
+					t.Fatalf("sign count = %d, want %d", used.SignCount, i)
+				}
+				user.Credentials[0] = *used
+			}
+		})
+	}
+}
+
+func TestRegistrationExcludesExistingPasskeys(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	options, _, err := rp.BeginRegistration(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.CredentialExcludeList) != 1 || string(options.CredentialExcludeList[0].CredentialID) != string(authenticator.credentialID) {
+		t.Fatalf("exclude list = %+v", options.CredentialExcludeList)
+	}
+	if options.AuthenticatorSelection.UserVerification != "required" {
+		t.Fatalf("user verification = %q", options.AuthenticatorSelection.UserVerification)
+	}
+}
+
+func TestRegistrationRejectsUnsupportedAttestation(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	authenticator := newSoftAuthenticator(t)
+
+	_, err := register(t, rp, testUser(), authenticator, "fido-u2f", authenticator.packedSelf)
+	if !errors.Is(err, ErrUnsupportedAttestation) {
+		t.Fatalf("err = %v, want ErrUnsupportedAttestation", err)
+	}
+}
+
+func TestRegistrationRejectsBadPackedSignature(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	authenticator := newSoftAuthenticator(t)
+	other := newSoftAuthenticator(t)
+
+	_, err := register(t, rp, testUser(), authenticator, AttestationPacked, other.packedSelf)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestRegistrationRejectsWrongOrigin(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	authenticator := newSoftAuthenticator(t)
+	authenticator.origin = "https://evil.example"
+
+	_, err := register(t, rp, testUser(), authenticator, AttestationNone, nil)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestRegistrationRejectsOtherChallenge(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+
+	options, _, err := rp.BeginRegistration(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, session, err := rp.BeginRegistration(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := authenticator.create(user, options.Challenge.String(), AttestationNone, nil)
+	if _, err := rp.FinishRegistration(user, session, response); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestRegistrationRequiresUserVerification(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	authenticator := newSoftAuthenticator(t)
+	authenticator.flags = flagUserPresent
+
+	_, err := register(t, rp, testUser(), authenticator, AttestationNone, nil)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestLoginRejectsSignCountRegression(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	user.Credentials[0].SignCount = 10
+
+	// A clone still counting from where the original was copied.
+	authenticator.signCount = 4
+	if _, err := login(t, rp, user, authenticator); !errors.Is(err, ErrSignCount) {
+		t.Fatalf("err = %v, want ErrSignCount", err)
+	}
+
+	authenticator.signCount = 10
+	used, err := login(t, rp, user, authenticator)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if used.SignCount != 11 {
+		t.Fatalf("sign count = %d, want 11", used.SignCount)
+	}
+}
+
+func TestLoginAcceptsAuthenticatorsWithoutCounter(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	// Synced passkeys always report a zero counter.
+	authenticator.noCounter = true
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := login(t, rp, user, authenticator); err != nil {
+			t.Fatalf("FinishLogin: %v", err)
+		}
+	}
+}
+
+func TestLoginRequiresUserVerification(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	authenticator.flags = flagUserPresent
+	if _, err := login(t, rp, user, authenticator); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestLoginRejectsUnknownCredential(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	stranger := newSoftAuthenticator(t)
+	stranger.userHandle = user.Handle
+	if _, err := login(t, rp, user, stranger); !errors.Is(err, ErrUnknownCredential) {
+		t.Fatalf("err = %v, want ErrUnknownCredential", err)
+	}
+}
+
+func TestLoginRejectsForgedSignature(t *testing.T) {
+	rp := newTestRelyingParty(t)
+	user := testUser()
+	authenticator := newSoftAuthenticator(t)
+	if _, err := register(t, rp, user, authenticator, AttestationNone, nil); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	forger := newSoftAuthenticator(t)
+	forger.credentialID = authenticator.credentialID
+	forger.userHandle = user.Handle
+	if _, err := login(t, rp, user, forger); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("err = %v, want ErrInvalidResponse", err)
+	}
+}
@@ -0,0 +1,52 @@
+package platform
+
+import (
+	"net/url"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+// Facebook handles watch, video, reel and fb.watch links. Numeric video IDs
+// canonicalize to /watch/?v=ID; share links that hide the ID are keyed by
+// their share code.
+type Facebook struct{}
+
+func (Facebook) Source() string { return constants.SourceFacebook }
+
+func (Facebook) Hosts() []string {
+	return []string{"facebook.com", "fb.com", "fb.watch", "fb.me"}
+}
+
+func (Facebook) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+	host := u.Hostname()
+
+	if matchesHost(host, []string{"fb.watch"}) {
+		if len(segments) != 1 || !shortCodePattern.MatchString(segments[0]) {
+			return "", "", false
+		}
+		return segments[0], "https://fb.watch/" + segments[0] + "/", true
+	}
+
+	var id string
+	switch {
+	case len(segments) == 1 && (segments[0] == "watch" || segments[0] == "video.php"):
+		id = u.Query().Get("v")
+	case len(segments) == 2 && segments[0] == "reel":
+		id = segments[1]
+	case len(segments) >= 3 && segments[1] == "videos":
+		// /{page}/videos/{id} or /{page}/videos/{slug}/{id}
+		id = segments[len(segments)-1]
+	case len(segments) == 3 && segments[0] == "share" && (segments[1] == "v" || segments[1] == "r"):
+		code := segments[2]
+		if !shortCodePattern.MatchString(code) {
+			return "", "", false
+		}
+		return "share:" + code, "https://www.facebook.com/share/" + segments[1] + "/" + code + "/", true
+	}
+
+	if !numericIDPattern.MatchString(id) {
+		return "", "", false
+	}
+	return id, "https://www.facebook.com/watch/?v=" + id, true
+}
@@ -0,0 +1,47 @@
+package platform
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+var instagramCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Instagram handles posts, reels and IGTV links, with or without the
+// username prefix. The shortcode identifies the media regardless of the
+// path kind, so all of them canonicalize to /p/CODE/.
+type Instagram struct{}
+
+func (Instagram) Source() string { return constants.SourceInstagram }
+
+func (Instagram) Hosts() []string {
+	return []string{"instagram.com", "instagr.am"}
+}
+
+func (Instagram) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	// Drop a leading username: /{user}/reel/{code}/
+	if len(segments) >= 3 && isInstagramMediaKind(segments[1]) {
+		segments = segments[1:]
+	}
+	if len(segments) < 2 || !isInstagramMediaKind(segments[0]) {
+		return "", "", false
+	}
+
+	code := segments[1]
+	if !instagramCodePattern.MatchString(code) {
+		return "", "", false
+	}
+	return code, "https://www.instagram.com/p/" + code + "/", true
+}
+
+func isInstagramMediaKind(segment string) bool {
+	switch segment {
+	case "p", "reel", "reels", "tv":
+		return true
+	}
+	return false
+}
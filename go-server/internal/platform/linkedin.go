@@ -0,0 +1,46 @@
+package platform
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+var (
+	linkedinURNPattern  = regexp.MustCompile(`^urn:li:(activity|ugcPost|share):([0-9]+)$`)
+	linkedinPostPattern = regexp.MustCompile(`-(activity|ugcPost|share)-([0-9]+)(?:-|$)`)
+)
+
+// LinkedIn handles /feed/update/urn:li:... and /posts/{slug}-activity-ID
+// links. They canonicalize to the feed/update URN form.
+type LinkedIn struct{}
+
+func (LinkedIn) Source() string { return constants.SourceLinkedIn }
+
+func (LinkedIn) Hosts() []string {
+	return []string{"linkedin.com", "lnkd.in"}
+}
+
+func (LinkedIn) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	var kind, id string
+	switch {
+	case len(segments) >= 3 && segments[len(segments)-3] == "feed" && segments[len(segments)-2] == "update":
+		// /feed/update/{urn} and /embed/feed/update/{urn}
+		if m := linkedinURNPattern.FindStringSubmatch(segments[len(segments)-1]); m != nil {
+			kind, id = m[1], m[2]
+		}
+	case len(segments) == 2 && segments[0] == "posts":
+		if m := linkedinPostPattern.FindStringSubmatch(segments[1]); m != nil {
+			kind, id = m[1], m[2]
+		}
+	}
+
+	if id == "" {
+		return "", "", false
+	}
+	contentID := kind + ":" + id
+	return contentID, "https://www.linkedin.com/feed/update/urn:li:" + contentID + "/", true
+}
@@ -0,0 +1,154 @@
+// Package platform recognises the social video platforms links are shared
+// from and reduces their URLs to a canonical form, so the same video saved
+// through different share links can be detected as a duplicate.
+package platform
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+var ErrInvalidURL = errors.New("platform: URL must be an absolute http or https URL")
+
+// Match is the result of resolving a URL. ContentID is empty when the URL
+// belongs to a platform but does not point at a single piece of content
+// (a profile page, for example).
+type Match struct {
+	Source       string
+	ContentID    string
+	CanonicalURL string
+}
+
+// Platform parses URLs for a single site. Parse is only called for URLs whose
+// host is in Hosts (or a subdomain of one of them).
+type Platform interface {
+	Source() string
+	Hosts() []string
+	Parse(u *url.URL) (contentID, canonicalURL string, ok bool)
+}
+
+type Registry struct {
+	platforms []Platform
+}
+
+func NewRegistry(platforms ...Platform) *Registry {
+	return &Registry{platforms: platforms}
+}
+
+// Default knows every platform the app supports.
+var Default = NewRegistry(
+	YouTube{},
+	Instagram{},
+	TikTok{},
+	Facebook{},
+	Twitter{},
+	LinkedIn{},
+	Vimeo{},
+)
+
+// Resolve identifies the platform of rawURL and returns its canonical form.
+// URLs from unknown sites resolve to constants.SourceOther with tracking
+// parameters removed and the rest of the URL normalized.
+func (r *Registry) Resolve(rawURL string) (*Match, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, ErrInvalidURL
+	}
+	u.Host = strings.ToLower(u.Host)
+
+	host := u.Hostname()
+	for _, p := range r.platforms {
+		if !matchesHost(host, p.Hosts()) {
+			continue
+		}
+		if contentID, canonical, ok := p.Parse(u); ok {
+			return &Match{Source: p.Source(), ContentID: contentID, CanonicalURL: canonical}, nil
+		}
+		return &Match{Source: p.Source(), CanonicalURL: normalize(u)}, nil
+	}
+
+	return &Match{Source: constants.SourceOther, CanonicalURL: normalize(u)}, nil
+}
+
+// Resolve resolves rawURL with the Default registry.
+func Resolve(rawURL string) (*Match, error) {
+	return Default.Resolve(rawURL)
+}
+
+// matchesHost reports whether host is one of hosts or a subdomain of one.
+// Matching is on label boundaries, so "box.com" does not match "x.com".
+func matchesHost(host string, hosts []string) bool {
+	host = strings.TrimSuffix(host, ".")
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSegments splits a URL path into its non-empty segments.
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+var trackingParams = map[string]bool{
+	"igshid":   true,
+	"igsh":     true,
+	"si":       true,
+	"feature":  true,
+	"fbclid":   true,
+	"mibextid": true,
+	"gclid":    true,
+	"dclid":    true,
+	"msclkid":  true,
+	"mc_cid":   true,
+	"mc_eid":   true,
+	"ref_src":  true,
+	"ref_url":  true,
+}
+
+// isTrackingParam reports whether a query parameter only carries
+// attribution data and can be dropped without changing the content.
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// normalize produces a stable form of a URL from an unrecognised site:
+// https, no "www.", no default port, no fragment, no trailing slash, no
+// tracking parameters and the remaining query sorted by key.
+func normalize(u *url.URL) string {
+	out := &url.URL{
+		Scheme: "https",
+		Host:   strings.TrimPrefix(u.Hostname(), "www."),
+		Path:   strings.TrimSuffix(u.Path, "/"),
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		out.Host += ":" + port
+	}
+
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	// Encode sorts by key.
+	out.RawQuery = query.Encode()
+
+	return out.String()
+}
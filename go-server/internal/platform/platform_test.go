@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Match
+	}{
+		{"https://youtu.be/dQw4w9WgXcQ?si=abc123", Match{constants.SourceYouTube, "dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=3", Match{constants.SourceYouTube, "dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{"https://m.youtube.com/shorts/dQw4w9WgXcQ?feature=share", Match{constants.SourceYouTube, "dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},
+		{"https://www.youtube.com/@somechannel", Match{constants.SourceYouTube, "", "https://youtube.com/@somechannel"}},
+
+		{"https://www.instagram.com/reel/C1a2b3C4d5E/?igshid=xyz&utm_source=ig_web", Match{constants.SourceInstagram, "C1a2b3C4d5E", "https://www.instagram.com/p/C1a2b3C4d5E/"}},
+		{"https://instagram.com/someone/p/C1a2b3C4d5E", Match{constants.SourceInstagram, "C1a2b3C4d5E", "https://www.instagram.com/p/C1a2b3C4d5E/"}},
+
+		{"https://www.tiktok.com/@chef/video/7301234567890123456?is_from_webapp=1", Match{constants.SourceTikTok, "7301234567890123456", "https://www.tiktok.com/@/video/7301234567890123456"}},
+		{"https://vm.tiktok.com/ZMabc123/", Match{constants.SourceTikTok, "ZMabc123", "https://vm.tiktok.com/ZMabc123/"}},
+
+		{"https://www.facebook.com/watch/?v=1234567890&mibextid=abc", Match{constants.SourceFacebook, "1234567890", "https://www.facebook.com/watch/?v=1234567890"}},
+		{"https://m.facebook.com/somepage/videos/a-title/1234567890/", Match{constants.SourceFacebook, "1234567890", "https://www.facebook.com/watch/?v=1234567890"}},
+		{"https://fb.watch/abcDEF123/", Match{constants.SourceFacebook, "abcDEF123", "https://fb.watch/abcDEF123/"}},
+
+		{"https://twitter.com/user/status/1712345678901234567?s=20", Match{constants.SourceTwitter, "1712345678901234567", "https://x.com/i/status/1712345678901234567"}},
+		{"https://x.com/user/status/1712345678901234567/video/1", Match{constants.SourceTwitter, "1712345678901234567", "https://x.com/i/status/1712345678901234567"}},
+
+		{"https://www.linkedin.com/posts/jane-doe_video-activity-7123456789012345678-AbCd", Match{constants.SourceLinkedIn, "activity:7123456789012345678", "https://www.linkedin.com/feed/update/urn:li:activity:7123456789012345678/"}},
+		{"https://www.linkedin.com/feed/update/urn:li:activity:7123456789012345678/", Match{constants.SourceLinkedIn, "activity:7123456789012345678", "https://www.linkedin.com/feed/update/urn:li:activity:7123456789012345678/"}},
+
+		{"https://vimeo.com/123456789", Match{constants.SourceVimeo, "123456789", "https://vimeo.com/123456789"}},
+		{"https://player.vimeo.com/video/123456789?h=abcdef", Match{constants.SourceVimeo, "123456789", "https://vimeo.com/123456789/abcdef"}},
+
+		{"https://box.com/s/file?utm_source=mail&b=2&a=1#top", Match{constants.SourceOther, "", "https://box.com/s/file?a=1&b=2"}},
+		{"HTTP://WWW.Example.com:80/path/", Match{constants.SourceOther, "", "https://example.com/path"}},
+	}
+
+	for _, tt := range tests {
+		got, err := Resolve(tt.url)
+		if err != nil {
+			t.Errorf("Resolve(%q) returned error: %v", tt.url, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("Resolve(%q) = %+v, want %+v", tt.url, *got, tt.want)
+		}
+	}
+}
+
+func TestResolveRejectsInvalidURLs(t *testing.T) {
+	for _, raw := range []string{"", "not a url", "/relative/path", "ftp://example.com/video", "javascript:alert(1)"} {
+		if _, err := Resolve(raw); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Resolve(%q) error = %v, want ErrInvalidURL", raw, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package platform
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+var (
+	numericIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	shortCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tiktokShortHosts = []string{"vm.tiktok.com", "vt.tiktok.com"}
+)
+
+// TikTok handles /@user/video/ID, /@user/photo/ID, /v/ID.html and the
+// vm./vt. short links. Short links can't be expanded without a request, so
+// they are keyed by their short code.
+type TikTok struct{}
+
+func (TikTok) Source() string { return constants.SourceTikTok }
+
+func (TikTok) Hosts() []string {
+	return []string{"tiktok.com"}
+}
+
+func (TikTok) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	if matchesHost(u.Hostname(), tiktokShortHosts) {
+		if len(segments) != 1 || !shortCodePattern.MatchString(segments[0]) {
+			return "", "", false
+		}
+		code := segments[0]
+		return code, "https://vm.tiktok.com/" + code + "/", true
+	}
+
+	var id string
+	switch {
+	case len(segments) >= 3 && strings.HasPrefix(segments[0], "@") && (segments[1] == "video" || segments[1] == "photo"):
+		id = segments[2]
+	case len(segments) == 2 && segments[0] == "v":
+		id = strings.TrimSuffix(segments[1], ".html")
+	case len(segments) >= 3 && segments[0] == "embed" && segments[1] == "v2":
+		id = segments[2]
+	}
+
+	if !numericIDPattern.MatchString(id) {
+		return "", "", false
+	}
+	// TikTok resolves the video regardless of the username, which users
+	// can change, so it is left out of the canonical form.
+	return id, "https://www.tiktok.com/@/video/" + id, true
+}
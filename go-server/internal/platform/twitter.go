@@ -0,0 +1,35 @@
+package platform
+
+import (
+	"net/url"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+// Twitter handles X and Twitter status links, including the /video/N and
+// /photo/N suffixes. They canonicalize to https://x.com/i/status/ID.
+type Twitter struct{}
+
+func (Twitter) Source() string { return constants.SourceTwitter }
+
+func (Twitter) Hosts() []string {
+	return []string{"x.com", "twitter.com"}
+}
+
+func (Twitter) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	var id string
+	switch {
+	case len(segments) >= 3 && segments[1] == "status":
+		// /{user}/status/{id} and /i/status/{id}
+		id = segments[2]
+	case len(segments) >= 4 && segments[0] == "i" && segments[1] == "web" && segments[2] == "status":
+		id = segments[3]
+	}
+
+	if !numericIDPattern.MatchString(id) {
+		return "", "", false
+	}
+	return id, "https://x.com/i/status/" + id, true
+}
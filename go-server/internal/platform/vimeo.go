@@ -0,0 +1,48 @@
+package platform
+
+import (
+	"net/url"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+// Vimeo handles vimeo.com/ID, channel and group video pages and the
+// player.vimeo.com embed. Unlisted videos keep their privacy hash, since
+// the video can't be opened without it.
+type Vimeo struct{}
+
+func (Vimeo) Source() string { return constants.SourceVimeo }
+
+func (Vimeo) Hosts() []string {
+	return []string{"vimeo.com"}
+}
+
+func (Vimeo) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	var id, hash string
+	switch {
+	case matchesHost(u.Hostname(), []string{"player.vimeo.com"}):
+		if len(segments) == 2 && segments[0] == "video" {
+			id = segments[1]
+			hash = u.Query().Get("h")
+		}
+	case len(segments) >= 1 && numericIDPattern.MatchString(segments[0]):
+		id = segments[0]
+		if len(segments) == 2 {
+			hash = segments[1]
+		}
+	case len(segments) == 3 && segments[0] == "channels":
+		id = segments[2]
+	case len(segments) == 4 && segments[0] == "groups" && segments[2] == "videos":
+		id = segments[3]
+	}
+
+	if !numericIDPattern.MatchString(id) {
+		return "", "", false
+	}
+	if hash != "" && shortCodePattern.MatchString(hash) {
+		return id, "https://vimeo.com/" + id + "/" + hash, true
+	}
+	return id, "https://vimeo.com/" + id, true
+}
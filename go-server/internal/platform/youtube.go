@@ -0,0 +1,44 @@
+package platform
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// YouTube handles watch, Shorts, live, embed and youtu.be links. All of them
+// canonicalize to https://www.youtube.com/watch?v=ID.
+type YouTube struct{}
+
+func (YouTube) Source() string { return constants.SourceYouTube }
+
+func (YouTube) Hosts() []string {
+	return []string{"youtube.com", "youtu.be", "youtube-nocookie.com"}
+}
+
+func (YouTube) Parse(u *url.URL) (string, string, bool) {
+	segments := pathSegments(u)
+
+	var id string
+	switch {
+	case matchesHost(u.Hostname(), []string{"youtu.be"}):
+		if len(segments) > 0 {
+			id = segments[0]
+		}
+	case len(segments) == 1 && segments[0] == "watch":
+		id = u.Query().Get("v")
+	case len(segments) >= 2:
+		switch segments[0] {
+		case "shorts", "live", "embed", "v", "e":
+			id = segments[1]
+		}
+	}
+
+	if !youtubeIDPattern.MatchString(id) {
+		return "", "", false
+	}
+	return id, "https://www.youtube.com/watch?v=" + id, true
+}
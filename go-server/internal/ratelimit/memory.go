@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped from memory.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled, after which it is the
+	// same as no bucket at all.
+	full time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore returns a process-local Store, for tests and single
+// instance setups.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Take(ctx context.Context, buckets ...Bucket) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for other, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, other)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	refilled := make([]*bucket, len(buckets))
+	allowed := true
+	for i, spec := range buckets {
+		refilled[i] = s.refill(spec, now)
+		if refilled[i].tokens < 1 {
+			allowed = false
+		}
+	}
+
+	results := make([]Result, len(buckets))
+	for i, spec := range buckets {
+		b := refilled[i]
+		hasToken := b.tokens >= 1
+		if allowed {
+			b.tokens--
+		}
+		results[i] = newResult(spec.Limit, hasToken, b.tokens)
+		b.full = now.Add(results[i].ResetAfter)
+	}
+	return results, nil
+}
+
+// refill returns spec's bucket with the tokens it has gained since it was
+// last used.
+func (s *memoryStore) refill(spec Bucket, now time.Time) *bucket {
+	capacity := float64(spec.Limit.Requests)
+	b, ok := s.buckets[spec.Key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[spec.Key] = b
+	}
+
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * spec.Limit.perSecond()
+		if b.tokens > capacity {
+			b.tokens = capacity
+		}
+		b.updated = now
+	}
+	return b
+}
@@ -0,0 +1,150 @@
+// Package ratelimit limits requests with token buckets. Buckets live in a
+// Store: in process for a single instance, or in Redis so every replica
+// shares them.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket holding up to Requests tokens, refilled at
+// Requests per Period. A full bucket allows a burst of Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// perSecond is the refill rate in tokens per second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the state of a bucket after a request.
+type Result struct {
+	// Allowed reports whether the bucket had a token for the request.
+	Allowed bool
+	Limit   Limit
+	// Remaining is how many requests the bucket allows right now.
+	Remaining int
+	// RetryAfter is how long until the next request is allowed; zero
+	// while Remaining is above zero.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Bucket names a token bucket and the limit it refills at.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+type Store interface {
+	// Take spends a token from each of buckets when every one of them has
+	// one, and otherwise spends none, so a request denied by one limit
+	// does not count against the others. Results are in the order of
+	// buckets; the request is allowed when all of them are.
+	Take(ctx context.Context, buckets ...Bucket) ([]Result, error)
+}
+
+// newResult describes a bucket left with tokens after a request.
+func newResult(limit Limit, allowed bool, tokens float64) Result {
+	rate := limit.perSecond()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((float64(limit.Requests) - tokens) / rate),
+	}
+	if tokens < 1 {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// What a Rule keys its buckets by.
+const (
+	KeyIP   = "ip"
+	KeyUser = "user"
+)
+
+// Rule limits requests per client IP or per signed-in user.
+type Rule struct {
+	Key   string
+	Limit Limit
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s:%d/%s", r.Key, r.Limit.Requests, r.Limit.Period)
+}
+
+// Policy maps route group names to their rules.
+type Policy map[string][]Rule
+
+// ParsePolicy reads a policy such as
+//
+//	auth=ip:30/1m;links=user:120/1m,ip:600/1m
+//
+// Groups are separated by semicolons and each group's rules by commas. A
+// rule is the key ("ip" or "user"), the number of requests and the period
+// they refill over, as a Go duration.
+func ParsePolicy(spec string) (Policy, error) {
+	policy := Policy{}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		group, rulesSpec, ok := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("ratelimit: %q: want group=rules", entry)
+		}
+		if _, dup := policy[group]; dup {
+			return nil, fmt.Errorf("ratelimit: group %q is listed twice", group)
+		}
+
+		var rules []Rule
+		for _, ruleSpec := range strings.Split(rulesSpec, ",") {
+			rule, err := parseRule(strings.TrimSpace(ruleSpec))
+			if err != nil {
+				return nil, fmt.Errorf("ratelimit: group %q: %w", group, err)
+			}
+			rules = append(rules, rule)
+		}
+		policy[group] = rules
+	}
+	return policy, nil
+}
+
+func parseRule(spec string) (Rule, error) {
+	key, limitSpec, ok := strings.Cut(spec, ":")
+	if !ok || (key != KeyIP && key != KeyUser) {
+		return Rule{}, fmt.Errorf("rule %q: want ip: or user: followed by requests/period", spec)
+	}
+
+	requestsSpec, periodSpec, ok := strings.Cut(limitSpec, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("rule %q: want requests/period", spec)
+	}
+	requests, err := strconv.Atoi(requestsSpec)
+	if err != nil || requests < 1 {
+		return Rule{}, fmt.Errorf("rule %q: requests must be a positive number", spec)
+	}
+	period, err := time.ParseDuration(periodSpec)
+	if err != nil || period <= 0 {
+		return Rule{}, fmt.Errorf("rule %q: period must be a positive duration such as 1m", spec)
+	}
+
+	return Rule{Key: key, Limit: Limit{Requests: requests, Period: period}}, nil
+}
@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// storeUnderTest is a Store with a clock the test can move.
+type storeUnderTest struct {
+	store   Store
+	advance func(time.Duration)
+}
+
+func storesUnderTest(t *testing.T) map[string]storeUnderTest {
+	now := time.Now()
+	memory := NewMemoryStore().(*memoryStore)
+	memory.now = func() time.Time { return now }
+
+	server := miniredis.RunT(t)
+	server.SetTime(now)
+	redisNow := now
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]storeUnderTest{
+		"memory": {
+			store:   memory,
+			advance: func(d time.Duration) { now = now.Add(d) },
+		},
+		"redis": {
+			store: NewRedisStore(client),
+			advance: func(d time.Duration) {
+				redisNow = redisNow.Add(d)
+				server.SetTime(redisNow)
+				server.FastForward(d)
+			},
+		},
+	}
+}
+
+func take(t *testing.T, store Store, key string, limit Limit) Result {
+	t.Helper()
+	results, err := store.Take(context.Background(), Bucket{Key: key, Limit: limit})
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	return results[0]
+}
+
+func TestStoreAllowsBurstThenRefills(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	for name, s := range storesUnderTest(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := 2; i >= 0; i-- {
+				result := take(t, s.store, "burst", limit)
+				if !result.Allowed || result.Remaining != i {
+					t.Fatalf("request %d = %+v, want allowed with %d remaining", 3-i, result, i)
+				}
+			}
+
+			denied := take(t, s.store, "burst", limit)
+			if denied.Allowed || denied.Remaining != 0 {
+				t.Fatalf("fourth request = %+v, want denied", denied)
+			}
+			if denied.RetryAfter <= 0 || denied.RetryAfter > time.Second {
+				t.Fatalf("RetryAfter = %v, want up to one token's refill (1s)", denied.RetryAfter)
+			}
+			if denied.ResetAfter <= 2*time.Second || denied.ResetAfter > 3*time.Second {
+				t.Fatalf("ResetAfter = %v, want about 3s", denied.ResetAfter)
+			}
+
+			s.advance(time.Second)
+			if result := take(t, s.store, "burst", limit); !result.Allowed {
+				t.Fatalf("request after a refill = %+v, want allowed", result)
+			}
+			if result := take(t, s.store, "burst", limit); result.Allowed {
+				t.Fatalf("second request after one refill = %+v, want denied", result)
+			}
+
+			if result := take(t, s.store, "other", limit); !result.Allowed || result.Remaining != 2 {
+				t.Fatalf("another key = %+v, want its own full bucket", result)
+			}
+		})
+	}
+}
+
+func TestStoreRefillStopsAtCapacity(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Minute}
+
+	for name, s := range storesUnderTest(t) {
+		t.Run(name, func(t *testing.T) {
+			take(t, s.store, "idle", limit)
+			s.advance(time.Hour)
+
+			result := take(t, s.store, "idle", limit)
+			if !result.Allowed || result.Remaining != 1 {
+				t.Fatalf("after a long idle = %+v, want allowed with 1 remaining", result)
+			}
+		})
+	}
+}
+
+func TestStoreTakesFromAllBucketsOrNone(t *testing.T) {
+	tight := Bucket{Key: "tight", Limit: Limit{Requests: 1, Period: time.Hour}}
+	loose := Bucket{Key: "loose", Limit: Limit{Requests: 3, Period: time.Hour}}
+
+	for name, s := range storesUnderTest(t) {
+		t.Run(name, func(t *testing.T) {
+			results, err := s.store.Take(context.Background(), tight, loose)
+			if err != nil {
+				t.Fatalf("Take: %v", err)
+			}
+			if !results[0].Allowed || !results[1].Allowed || results[1].Remaining != 2 {
+				t.Fatalf("first request = %+v, want allowed by both", results)
+			}
+
+			for i := 0; i < 3; i++ {
+				results, err = s.store.Take(context.Background(), tight, loose)
+				if err != nil {
+					t.Fatalf("Take: %v", err)
+				}
+				if results[0].Allowed {
+					t.Fatalf("request over the tight limit = %+v, want denied", results)
+				}
+			}
+
+			if result := take(t, s.store, loose.Key, loose.Limit); !result.Allowed || result.Remaining != 1 {
+				t.Fatalf("loose bucket after denials = %+v, want 1 remaining", result)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(" auth=ip:30/1m ; links=user:120/1m,ip:600/1m;")
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	want := map[string][]Rule{
+		"auth": {{Key: KeyIP, Limit: Limit{Requests: 30, Period: time.Minute}}},
+		"links": {
+			{Key: KeyUser, Limit: Limit{Requests: 120, Period: time.Minute}},
+			{Key: KeyIP, Limit: Limit{Requests: 600, Period: time.Minute}},
+		},
+	}
+	if len(policy) != len(want) {
+		t.Fatalf("policy = %v, want %v", policy, want)
+	}
+	for group, rules := range want {
+		if len(policy[group]) != len(rules) {
+			t.Fatalf("policy[%q] = %v, want %v", group, policy[group], rules)
+		}
+		for i := range rules {
+			if policy[group][i] != rules[i] {
+				t.Fatalf("policy[%q][%d] = %v, want %v", group, i, policy[group][i], rules[i])
+			}
+		}
+	}
+
+	if policy, err := ParsePolicy(""); err != nil || len(policy) != 0 {
+		t.Fatalf("ParsePolicy(\"\") = %v, %v; want an empty policy", policy, err)
+	}
+}
+
+func TestParsePolicyRejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"auth",
+		"=ip:1/1m",
+		"auth=session:1/1m",
+		"auth=ip:0/1m",
+		"auth=ip:ten/1m",
+		"auth=ip:10",
+		"auth=ip:10/soon",
+		"auth=ip:10/-1m",
+		"auth=ip:10/1m;auth=user:10/1m",
+	} {
+		if _, err := ParsePolicy(spec); err == nil {
+			t.Errorf("ParsePolicy(%q) succeeded, want an error", spec)
+		}
+	}
+}
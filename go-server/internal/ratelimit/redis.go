@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills every bucket in KEYS and, when all of them have a
+// token, spends one from each, atomically. ARGV holds each bucket's
+// capacity and period in turn. It uses the Redis server's clock so replicas
+// with skewed clocks agree, and a bucket expires once it would be full
+// again.
+var takeScript = redis.NewScript(`
+local now = redis.call('TIME')
+now = tonumber(now[1]) * 1000000 + tonumber(now[2])
+
+local buckets = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[2 * i - 1])
+	local period = tonumber(ARGV[2 * i])
+
+	local state = redis.call('HMGET', key, 'tokens', 'updated')
+	local tokens = tonumber(state[1])
+	local updated = tonumber(state[2])
+	if tokens == nil or updated == nil then
+		tokens = capacity
+		updated = now
+	end
+
+	if now > updated then
+		tokens = math.min(capacity, tokens + (now - updated) * capacity / period)
+		updated = now
+	end
+
+	if tokens < 1 then
+		allowed = 0
+	end
+	buckets[i] = {capacity = capacity, period = period, tokens = tokens, updated = updated}
+end
+
+local reply = {}
+for i, key in ipairs(KEYS) do
+	local bucket = buckets[i]
+	local has_token = 0
+	if bucket.tokens >= 1 then
+		has_token = 1
+	end
+	if allowed == 1 then
+		bucket.tokens = bucket.tokens - 1
+	end
+
+	redis.call('HSET', key, 'tokens', tostring(bucket.tokens), 'updated', tostring(bucket.updated))
+	redis.call('PEXPIRE', key, math.ceil((bucket.capacity - bucket.tokens) * bucket.period / bucket.capacity / 1000) + 1)
+
+	reply[2 * i - 1] = has_token
+	reply[2 * i] = tostring(bucket.tokens)
+end
+
+return reply
+`)
+
+const redisKeyPrefix = "ratelimit:"
+
+type redisStore struct {
+	client redis.Scripter
+}
+
+// NewRedisStore returns a Store keeping buckets in Redis, shared by every
+// server instance.
+func NewRedisStore(client redis.Scripter) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Take(ctx context.Context, buckets ...Bucket) ([]Result, error) {
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(buckets))
+	args := make([]interface{}, 0, 2*len(buckets))
+	for _, bucket := range buckets {
+		keys = append(keys, redisKeyPrefix+bucket.Key)
+		args = append(args, bucket.Limit.Requests, bucket.Limit.Period.Microseconds())
+	}
+
+	reply, err := takeScript.Run(ctx, s.client, keys, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 2*len(buckets) {
+		return nil, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+
+	results := make([]Result, 0, len(buckets))
+	for i, bucket := range buckets {
+		hasToken, ok := reply[2*i].(int64)
+		tokensText, isText := reply[2*i+1].(string)
+		tokens, err := strconv.ParseFloat(tokensText, 64)
+		if !ok || !isText || err != nil {
+			return nil, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+		}
+		results = append(results, newResult(bucket.Limit, hasToken == 1, tokens))
+	}
+	return results, nil
+}
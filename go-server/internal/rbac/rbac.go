@@ -0,0 +1,54 @@
+// Package rbac maps user roles to the permissions they grant. Routes check
+// permissions rather than roles where they can, so a new role only needs
+// a row in the matrix below.
+package rbac
+
+import "github.com/video-mobile-app/go-server/internal/constants"
+
+// Permission is an action on a kind of resource, written "resource:action".
+type Permission string
+
+const (
+	// PermissionUsersRead allows looking up any user's account.
+	PermissionUsersRead Permission = "users:read"
+	// PermissionUsersManageRoles allows changing another user's role.
+	PermissionUsersManageRoles Permission = "users:manage_roles"
+)
+
+// permissions is the role→permission matrix. Every user may manage their
+// own links, tags and collections; that needs no permission.
+var permissions = map[string][]Permission{
+	constants.RoleUser:  {},
+	constants.RoleAdmin: {PermissionUsersRead},
+	constants.RoleSuperAdmin: {
+		PermissionUsersRead,
+		PermissionUsersManageRoles,
+	},
+}
+
+// IsRole reports whether role is a known role.
+func IsRole(role string) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// Can reports whether role grants permission. Unknown roles grant nothing.
+func Can(role string, permission Permission) bool {
+	for _, granted := range permissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes reports whether role grants every permission other does, so
+// moving a user from other to role takes nothing away.
+func Includes(role, other string) bool {
+	for _, permission := range permissions[other] {
+		if !Can(role, permission) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/video-mobile-app/go-server/internal/constants"
+)
+
+func TestCan(t *testing.T) {
+	for _, tt := range []struct {
+		role       string
+		permission Permission
+		want       bool
+	}{
+		{constants.RoleUser, PermissionUsersRead, false},
+		{constants.RoleUser, PermissionUsersManageRoles, false},
+		{constants.RoleAdmin, PermissionUsersRead, true},
+		{constants.RoleAdmin, PermissionUsersManageRoles, false},
+		{constants.RoleSuperAdmin, PermissionUsersRead, true},
+		{constants.RoleSuperAdmin, PermissionUsersManageRoles, true},
+		{"", PermissionUsersRead, false},
+		{"root", PermissionUsersRead, false},
+	} {
+		if got := Can(tt.role, tt.permission); got != tt.want {
+			t.Errorf("Can(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestIsRole(t *testing.T) {
+	for _, role := range []string{constants.RoleUser, constants.RoleAdmin, constants.RoleSuperAdmin} {
+		if !IsRole(role) {
+			t.Errorf("IsRole(%q) = false", role)
+		}
+	}
+	if IsRole("root") || IsRole("") {
+		t.Error("IsRole accepted an unknown role")
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	for _, tt := range []struct {
+		role, other string
+		want        bool
+	}{
+		{constants.RoleSuperAdmin, constants.RoleAdmin, true},
+		{constants.RoleAdmin, constants.RoleUser, true},
+		{constants.RoleAdmin, constants.RoleAdmin, true},
+		{constants.RoleAdmin, constants.RoleSuperAdmin, false},
+		{constants.RoleUser, constants.RoleAdmin, false},
+		{"root", constants.RoleUser, true},
+		{"root", constants.RoleAdmin, false},
+	} {
+		if got := Includes(tt.role, tt.other); got != tt.want {
+			t.Errorf("Includes(%q, %q) = %v, want %v", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// PositionGap is the spacing between consecutive item positions after
+// appending or rebalancing.
+const PositionGap int64 = 1024
+
+// CollectionWithCount is a collection plus the number of links in it.
+type CollectionWithCount struct {
+	models.Collection `gorm:"embedded"`
+	ItemCount         int64
+}
+
+// CollectionItemRow is a link as it appears in a collection.
+type CollectionItemRow struct {
+	models.Link `gorm:"embedded"`
+	Position    int64
+	AddedAt     time.Time
+}
+
+type CollectionRepository interface {
+	Create(collection *models.Collection) error
+	Update(collection *models.Collection) error
+	Delete(id, userID uuid.UUID) (bool, error)
+	FindByID(id, userID uuid.UUID) (*CollectionWithCount, error)
+	FindAllByUser(userID uuid.UUID, after *pagination.Cursor, limit int) ([]CollectionWithCount, error)
+
+	FindItem(collectionID, linkID uuid.UUID) (*models.CollectionItem, error)
+	FindItems(collectionID uuid.UUID, after *pagination.Cursor, limit int) ([]CollectionItemRow, error)
+	AddItem(item *models.CollectionItem) error
+	RemoveItem(collectionID, linkID uuid.UUID) (bool, error)
+	UpdateItemPosition(collectionID, linkID uuid.UUID, position int64) error
+	// FirstPosition and LastPosition return ok=false for an empty collection.
+	FirstPosition(collectionID uuid.UUID, excludeLinkID uuid.UUID) (int64, bool, error)
+	LastPosition(collectionID uuid.UUID) (int64, bool, error)
+	// NextPosition returns the position of the item that follows position,
+	// ignoring excludeLinkID.
+	NextPosition(collectionID uuid.UUID, position int64, excludeLinkID uuid.UUID) (int64, bool, error)
+	Rebalance(collectionID uuid.UUID) error
+}
+
+type collectionRepository struct{}
+
+func NewCollectionRepository() CollectionRepository {
+	return &collectionRepository{}
+}
+
+func (r *collectionRepository) Create(collection *models.Collection) error {
+	return database.DB.Create(collection).Error
+}
+
+func (r *collectionRepository) Update(collection *models.Collection) error {
+	return database.DB.Save(collection).Error
+}
+
+func (r *collectionRepository) Delete(id, userID uuid.UUID) (bool, error) {
+	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Collection{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *collectionRepository) withCount() *gorm.DB {
+	return database.DB.Model(&models.Collection{}).
+		Select("collections.*, (SELECT COUNT(*) FROM collection_items WHERE collection_items.collection_id = collections.id) AS item_count")
+}
+
+func (r *collectionRepository) FindByID(id, userID uuid.UUID) (*CollectionWithCount, error) {
+	var collection CollectionWithCount
+	err := r.withCount().
+		Where("collections.id = ? AND collections.user_id = ?", id, userID).
+		Take(&collection).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// FindAllByUser returns up to limit collections newest first, starting
+// after the given cursor when one is set.
+func (r *collectionRepository) FindAllByUser(userID uuid.UUID, after *pagination.Cursor, limit int) ([]CollectionWithCount, error) {
+	query := r.withCount().Where("collections.user_id = ?", userID)
+
+	if after != nil {
+		query = query.Where("(collections.created_at, collections.id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var collections []CollectionWithCount
+	err := query.Order("collections.created_at DESC, collections.id DESC").Limit(limit).Find(&collections).Error
+	if err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func (r *collectionRepository) FindItem(collectionID, linkID uuid.UUID) (*models.CollectionItem, error) {
+	var item models.CollectionItem
+	err := database.DB.Where("collection_id = ? AND link_id = ?", collectionID, linkID).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindItems returns up to limit links of a collection in position order,
+// starting after the given cursor when one is set. The cursor carries the
+// position in Rank and the link ID in ID.
+func (r *collectionRepository) FindItems(collectionID uuid.UUID, after *pagination.Cursor, limit int) ([]CollectionItemRow, error) {
+	query := database.DB.Model(&models.Link{}).
+		Select(linkColumns()+", collection_items.position, collection_items.added_at").
+		Joins("JOIN collection_items ON collection_items.link_id = links.id").
+		Where("collection_items.collection_id = ?", collectionID)
+
+	if after != nil {
+		query = query.Where("(collection_items.position, links.id) > (?, ?)", int64(after.Rank), after.ID)
+	}
+
+	var rows []CollectionItemRow
+	err := query.Order("collection_items.position, links.id").Limit(limit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *collectionRepository) AddItem(item *models.CollectionItem) error {
+	return database.DB.Create(item).Error
+}
+
+func (r *collectionRepository) RemoveItem(collectionID, linkID uuid.UUID) (bool, error) {
+	result := database.DB.Where("collection_id = ? AND link_id = ?", collectionID, linkID).Delete(&models.CollectionItem{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *collectionRepository) UpdateItemPosition(collectionID, linkID uuid.UUID, position int64) error {
+	return database.DB.Model(&models.CollectionItem{}).
+		Where("collection_id = ? AND link_id = ?", collectionID, linkID).
+		Update("position", position).Error
+}
+
+func (r *collectionRepository) FirstPosition(collectionID uuid.UUID, excludeLinkID uuid.UUID) (int64, bool, error) {
+	return r.position(database.DB.
+		Where("collection_id = ? AND link_id <> ?", collectionID, excludeLinkID).
+		Order("position ASC"))
+}
+
+func (r *collectionRepository) LastPosition(collectionID uuid.UUID) (int64, bool, error) {
+	return r.position(database.DB.
+		Where("collection_id = ?", collectionID).
+		Order("position DESC"))
+}
+
+func (r *collectionRepository) NextPosition(collectionID uuid.UUID, position int64, excludeLinkID uuid.UUID) (int64, bool, error) {
+	return r.position(database.DB.
+		Where("collection_id = ? AND position > ? AND link_id <> ?", collectionID, position, excludeLinkID).
+		Order("position ASC"))
+}
+
+func (r *collectionRepository) position(query *gorm.DB) (int64, bool, error) {
+	var positions []int64
+	err := query.Model(&models.CollectionItem{}).Limit(1).Pluck("position", &positions).Error
+	if err != nil || len(positions) == 0 {
+		return 0, false, err
+	}
+	return positions[0], true, nil
+}
+
+// Rebalance renumbers a collection's items PositionGap apart, keeping their
+// order, so there is room to insert between any two again.
+func (r *collectionRepository) Rebalance(collectionID uuid.UUID) error {
+	return database.DB.Exec(`
+		UPDATE collection_items SET position = ordered.rn * ?
+		FROM (
+			SELECT link_id, ROW_NUMBER() OVER (ORDER BY position, link_id) AS rn
+			FROM collection_items
+			WHERE collection_id = ?
+		) ordered
+		WHERE collection_items.collection_id = ? AND collection_items.link_id = ordered.link_id`,
+		PositionGap, collectionID, collectionID,
+	).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type CollectionShareRepository interface {
+	Create(share *models.CollectionShare) error
+	FindByCollection(collectionID uuid.UUID) ([]models.CollectionShare, error)
+	// FindByTokenHash loads the share together with its collection.
+	FindByTokenHash(tokenHash string) (*models.CollectionShare, error)
+	Revoke(id, collectionID uuid.UUID) (bool, error)
+	RecordView(id uuid.UUID) error
+}
+
+type collectionShareRepository struct{}
+
+func NewCollectionShareRepository() CollectionShareRepository {
+	return &collectionShareRepository{}
+}
+
+func (r *collectionShareRepository) Create(share *models.CollectionShare) error {
+	return database.DB.Create(share).Error
+}
+
+func (r *collectionShareRepository) FindByCollection(collectionID uuid.UUID) ([]models.CollectionShare, error) {
+	var shares []models.CollectionShare
+	err := database.DB.Where("collection_id = ?", collectionID).Order("created_at DESC").Find(&shares).Error
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (r *collectionShareRepository) FindByTokenHash(tokenHash string) (*models.CollectionShare, error) {
+	var share models.CollectionShare
+	err := database.DB.Preload("Collection").Where("token_hash = ?", tokenHash).First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Revoke marks an active share as revoked. It reports false when the share
+// does not exist or was already revoked.
+func (r *collectionShareRepository) Revoke(id, collectionID uuid.UUID) (bool, error) {
+	result := database.DB.Model(&models.CollectionShare{}).
+		Where("id = ? AND collection_id = ? AND revoked_at IS NULL", id, collectionID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordView bumps the view counter in a single statement so concurrent
+// views are not lost.
+func (r *collectionShareRepository) RecordView(id uuid.UUID) error {
+	return database.DB.Model(&models.CollectionShare{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": time.Now(),
+		}).Error
+}
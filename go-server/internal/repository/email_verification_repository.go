@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository interface {
+	// Create stores a new token and invalidates the user's earlier ones,
+	// so only the latest email works.
+	Create(token *models.EmailVerificationToken) error
+	FindByTokenHash(tokenHash string) (*models.EmailVerificationToken, error)
+	// FindCreatedSince lists the user's tokens issued after since, newest
+	// first, for throttling resends.
+	FindCreatedSince(userID uuid.UUID, since time.Time) ([]models.EmailVerificationToken, error)
+	// Redeem uses the token and marks the user's email verified, provided
+	// the address has not changed since the token was issued. It returns
+	// false when the token was already used or has expired.
+	Redeem(token *models.EmailVerificationToken) (bool, error)
+}
+
+var errEmailChanged = errors.New("email changed since the token was issued")
+
+type emailVerificationRepository struct{}
+
+func NewEmailVerificationRepository() EmailVerificationRepository {
+	return &emailVerificationRepository{}
+}
+
+func (r *emailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *emailVerificationRepository) FindByTokenHash(tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	if err := database.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *emailVerificationRepository) FindCreatedSince(userID uuid.UUID, since time.Time) ([]models.EmailVerificationToken, error) {
+	var tokens []models.EmailVerificationToken
+	err := database.DB.
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *emailVerificationRepository) Redeem(token *models.EmailVerificationToken) (bool, error) {
+	redeemed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		result := tx.Model(&models.EmailVerificationToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		result = tx.Model(&models.User{}).
+			Where("id = ? AND email = ?", token.UserID, token.Email).
+			Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", now))
+		if result.Error != nil {
+			return result.Error
+		}
+		redeemed = result.RowsAffected > 0
+		if !redeemed {
+			// The address changed after the token was sent; roll back
+			// so the token is not spent.
+			return errEmailChanged
+		}
+		return nil
+	})
+	if errors.Is(err, errEmailChanged) {
+		return false, nil
+	}
+	return redeemed, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	Create(identity *models.UserIdentity) error
+	// CreateWithUser stores a new user together with its first identity.
+	CreateWithUser(user *models.User, identity *models.UserIdentity) error
+	FindByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	FindByUserAndProvider(userID uuid.UUID, provider string) (*models.UserIdentity, error)
+	TouchLastLogin(id uuid.UUID) error
+}
+
+type identityRepository struct{}
+
+func NewIdentityRepository() IdentityRepository {
+	return &identityRepository{}
+}
+
+func (r *identityRepository) Create(identity *models.UserIdentity) error {
+	return database.DB.Create(identity).Error
+}
+
+func (r *identityRepository) CreateWithUser(user *models.User, identity *models.UserIdentity) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *identityRepository) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := database.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) FindByUserAndProvider(userID uuid.UUID, provider string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := database.DB.Where("user_id = ? AND provider = ?", userID, provider).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) TouchLastLogin(id uuid.UUID) error {
+	return database.DB.Model(&models.UserIdentity{}).Where("id = ?", id).Update("last_login_at", time.Now()).Error
+}
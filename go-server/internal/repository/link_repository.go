@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type LinkFilter struct {
+	Search   string
+	Source   string
+	Category string
+	// Tags keeps links carrying any of the tag names, or all of them when
+	// MatchAllTags is set.
+	Tags         []string
+	MatchAllTags bool
+}
+
+const (
+	SearchModeWebsearch = "websearch"
+	SearchModePhrase    = "phrase"
+	SearchModePrefix    = "prefix"
+)
+
+type LinkSearchFilter struct {
+	Query    string
+	Mode     string
+	Source   string
+	Category string
+}
+
+// HighlightStart and HighlightStop surround matches in search highlights.
+// They are control characters rather than markup because the text comes
+// from third-party pages: callers escape it before turning these into
+// tags. Both are removed from the text before highlighting.
+const (
+	HighlightStart = "\x01"
+	HighlightStop  = "\x02"
+)
+
+// LinkSearchRow is a full-text search hit. TitleHighlight and Snippet are
+// plain text from ts_headline with matches between HighlightStart and
+// HighlightStop.
+type LinkSearchRow struct {
+	models.Link    `gorm:"embedded"`
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+type LinkRepository interface {
+	Create(link *models.Link) error
+	Update(link *models.Link) error
+	FindByID(id, userID uuid.UUID) (*models.Link, error)
+	FindByCanonicalURL(userID uuid.UUID, canonicalURL string) (*models.Link, error)
+	FindAllByUser(userID uuid.UUID, filter LinkFilter, after *pagination.Cursor, limit int) ([]models.Link, error)
+	Search(userID uuid.UUID, filter LinkSearchFilter, after *pagination.Cursor, limit int) ([]LinkSearchRow, error)
+	FuzzySearch(userID uuid.UUID, filter LinkSearchFilter, threshold float64, after *pagination.Cursor, limit int) ([]LinkSearchRow, error)
+}
+
+type linkRepository struct{}
+
+func NewLinkRepository() LinkRepository {
+	return &linkRepository{}
+}
+
+func (r *linkRepository) Create(link *models.Link) error {
+	return database.DB.Create(link).Error
+}
+
+func (r *linkRepository) Update(link *models.Link) error {
+	return database.DB.Save(link).Error
+}
+
+func (r *linkRepository) FindByID(id, userID uuid.UUID) (*models.Link, error) {
+	var link models.Link
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *linkRepository) FindByCanonicalURL(userID uuid.UUID, canonicalURL string) (*models.Link, error) {
+	var link models.Link
+	err := database.DB.Where("user_id = ? AND canonical_url = ?", userID, canonicalURL).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindAllByUser returns up to limit links newest first, starting after the
+// given cursor when one is set.
+func (r *linkRepository) FindAllByUser(userID uuid.UUID, filter LinkFilter, after *pagination.Cursor, limit int) ([]models.Link, error) {
+	query := database.DB.Where("user_id = ?", userID)
+
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		term := "%" + search + "%"
+		query = query.Where("(url ILIKE ? OR title ILIKE ?)", term, term)
+	}
+
+	if len(filter.Tags) > 0 {
+		tagged := database.DB.Model(&models.LinkTag{}).
+			Select("link_tags.link_id").
+			Joins("JOIN tags ON tags.id = link_tags.tag_id").
+			Where("tags.user_id = ? AND tags.name IN ?", userID, filter.Tags)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("link_tags.link_id").Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var links []models.Link
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Search runs a full-text query against links.search_vector and returns up
+// to limit hits ordered by relevance, then recency.
+func (r *linkRepository) Search(userID uuid.UUID, filter LinkSearchFilter, after *pagination.Cursor, limit int) ([]LinkSearchRow, error) {
+	tsquery := searchQueryExpr(filter.Mode, filter.Query)
+	rank := gorm.Expr("ts_rank_cd(links.search_vector, ?)", tsquery)
+
+	page := database.DB.Model(&models.Link{}).
+		Select("links.id, links.created_at, ? AS rank", rank).
+		Where("links.user_id = ? AND links.search_vector @@ ?", userID, tsquery)
+
+	if filter.Source != "" {
+		page = page.Where("links.source = ?", filter.Source)
+	}
+
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		page = page.Where("links.category = ?", category)
+	}
+
+	if after != nil {
+		page = page.Where("(?, links.created_at, links.id) < (?, ?, ?)", rank, after.Rank, after.CreatedAt, after.ID)
+	}
+
+	page = page.Order("rank DESC, links.created_at DESC, links.id DESC").Limit(limit)
+
+	// Headlines are only computed for the rows on this page.
+	var rows []LinkSearchRow
+	err := database.DB.Raw(`
+		SELECT `+linkColumns()+`, page.rank,
+			ts_headline('english', translate(coalesce(links.title, ''), ?, ''), ?,
+				? || ', HighlightAll=true') AS title_highlight,
+			ts_headline('english', translate(concat_ws(' ', links.description, links.author_name, links.notes), ?, ''), ?,
+				? || ', MaxFragments=2, MaxWords=30, MinWords=10') AS snippet
+		FROM (?) AS page
+		JOIN links ON links.id = page.id
+		ORDER BY page.rank DESC, page.created_at DESC, page.id DESC`,
+		highlightMarkers, tsquery, highlightSelectors,
+		highlightMarkers, tsquery, highlightSelectors,
+		page,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+var (
+	highlightMarkers   = HighlightStart + HighlightStop
+	highlightSelectors = "StartSel=" + HighlightStart + ", StopSel=" + HighlightStop
+)
+
+// FuzzySearch matches the query against title and creator name by pg_trgm
+// word similarity, tolerating typos. Rank is the better of the two
+// similarities; rows below threshold are excluded.
+func (r *linkRepository) FuzzySearch(userID uuid.UUID, filter LinkSearchFilter, threshold float64, after *pagination.Cursor, limit int) ([]LinkSearchRow, error) {
+	rank := gorm.Expr(
+		"GREATEST(word_similarity(?, coalesce(links.title, '')), word_similarity(?, coalesce(links.author_name, '')))",
+		filter.Query, filter.Query,
+	)
+
+	var rows []LinkSearchRow
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// The <% operator can use the trigram indexes but compares against
+		// this setting rather than a parameter.
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", strconv.FormatFloat(threshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+
+		query := tx.Model(&models.Link{}).
+			Select(linkColumns()+", ? AS rank", rank).
+			Where("links.user_id = ?", userID).
+			Where("(? <% links.title OR ? <% links.author_name)", filter.Query, filter.Query)
+
+		if filter.Source != "" {
+			query = query.Where("links.source = ?", filter.Source)
+		}
+
+		if category := strings.TrimSpace(filter.Category); category != "" {
+			query = query.Where("links.category = ?", category)
+		}
+
+		if after != nil {
+			query = query.Where("(?, links.created_at, links.id) < (?, ?, ?)", rank, after.Rank, after.CreatedAt, after.ID)
+		}
+
+		return query.Order("rank DESC, links.created_at DESC, links.id DESC").Limit(limit).Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// searchQueryExpr builds the tsquery for a search mode. websearch accepts
+// the syntax of web search engines ("quoted phrases", or, -excluded),
+// phrase requires the words in order and prefix matches words that start
+// with each term, for search-as-you-type.
+func searchQueryExpr(mode, text string) clause.Expr {
+	switch mode {
+	case SearchModePhrase:
+		return gorm.Expr("phraseto_tsquery('english', ?)", text)
+	case SearchModePrefix:
+		return gorm.Expr("to_tsquery('english', ?)", prefixQuery(text))
+	default:
+		return gorm.Expr("websearch_to_tsquery('english', ?)", text)
+	}
+}
+
+// prefixQuery turns free text into to_tsquery syntax where every word is a
+// prefix match: "pasta carb" becomes "pasta:* & carb:*". Anything that is
+// not a letter or digit is treated as a separator so user input can't
+// inject tsquery operators.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+var (
+	linkColumnsOnce sync.Once
+	linkColumnList  string
+)
+
+// linkColumns lists the models.Link columns qualified with the table name,
+// for raw queries that must not select links.search_vector.
+func linkColumns() string {
+	linkColumnsOnce.Do(func() {
+		s, err := schema.Parse(&models.Link{}, &sync.Map{}, database.DB.NamingStrategy)
+		if err != nil {
+			panic(err)
+		}
+		columns := make([]string, 0, len(s.DBNames))
+		for _, name := range s.DBNames {
+			columns = append(columns, "links."+name)
+		}
+		linkColumnList = strings.Join(columns, ", ")
+	})
+	return linkColumnList
+}
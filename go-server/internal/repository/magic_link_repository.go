@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepository interface {
+	// Create stores a new link and invalidates the address's earlier ones,
+	// so only the latest email works.
+	Create(token *models.MagicLinkToken) error
+	FindByID(id uuid.UUID) (*models.MagicLinkToken, error)
+	// FindCreatedSince lists the links issued for email after since,
+	// newest first, for throttling.
+	FindCreatedSince(email string, since time.Time) ([]models.MagicLinkToken, error)
+	// Redeem uses the link. It returns false when the link was already
+	// used or has expired.
+	Redeem(token *models.MagicLinkToken) (bool, error)
+}
+
+type magicLinkRepository struct{}
+
+func NewMagicLinkRepository() MagicLinkRepository {
+	return &magicLinkRepository{}
+}
+
+func (r *magicLinkRepository) Create(token *models.MagicLinkToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.MagicLinkToken{}).
+			Where("email = ? AND used_at IS NULL", token.Email).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *magicLinkRepository) FindByID(id uuid.UUID) (*models.MagicLinkToken, error) {
+	var token models.MagicLinkToken
+	if err := database.DB.Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *magicLinkRepository) FindCreatedSince(email string, since time.Time) ([]models.MagicLinkToken, error) {
+	var tokens []models.MagicLinkToken
+	err := database.DB.
+		Where("email = ? AND created_at > ?", email, since).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *magicLinkRepository) Redeem(token *models.MagicLinkToken) (bool, error) {
+	now := time.Now()
+
+	// The conditional update makes a link single-use even when two
+	// sign-ins race.
+	result := database.DB.Model(&models.MagicLinkToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
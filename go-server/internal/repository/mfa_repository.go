@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	// SetPendingSecret stores a new, not yet confirmed TOTP secret.
+	SetPendingSecret(userID uuid.UUID, encryptedSecret string) error
+	// Enable turns two-factor sign-in on and replaces the recovery codes.
+	Enable(userID uuid.UUID, step int64, codes []models.MFARecoveryCode) error
+	// Disable turns two-factor sign-in off and removes the secret and
+	// recovery codes.
+	Disable(userID uuid.UUID) error
+	// ConsumeStep records a used TOTP time step. It returns false when a
+	// code from that step or a later one was already used.
+	ConsumeStep(userID uuid.UUID, step int64) (bool, error)
+	// UseRecoveryCode spends a recovery code, returning false when it does
+	// not exist or was already used.
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error)
+}
+
+type mfaRepository struct{}
+
+func NewMFARepository() MFARepository {
+	return &mfaRepository{}
+}
+
+func (r *mfaRepository) SetPendingSecret(userID uuid.UUID, encryptedSecret string) error {
+	return database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":    encryptedSecret,
+			"totp_last_step": 0,
+		}).Error
+}
+
+func (r *mfaRepository) Enable(userID uuid.UUID, step int64, codes []models.MFARecoveryCode) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled_at": time.Now(),
+				"totp_last_step":  step,
+			}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *mfaRepository) Disable(userID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_secret":     nil,
+				"totp_enabled_at": nil,
+				"totp_last_step":  0,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error
+	})
+}
+
+func (r *mfaRepository) ConsumeStep(userID uuid.UUID, step int64) (bool, error) {
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mfaRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := database.DB.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
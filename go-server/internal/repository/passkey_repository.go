@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+)
+
+type PasskeyRepository interface {
+	Create(credential *models.WebAuthnCredential) error
+	FindByUser(userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	FindByCredentialID(credentialID []byte) (*models.WebAuthnCredential, error)
+	// RecordUse stores the counter and backup state of a sign-in. It
+	// returns false when another sign-in moved the counter since
+	// previousCount was read.
+	RecordUse(id uuid.UUID, previousCount, signCount int64, backupState bool) (bool, error)
+	// Delete removes one of the user's passkeys, returning false when the
+	// user has no passkey with that ID.
+	Delete(userID, id uuid.UUID) (bool, error)
+}
+
+type passkeyRepository struct{}
+
+func NewPasskeyRepository() PasskeyRepository {
+	return &passkeyRepository{}
+}
+
+func (r *passkeyRepository) Create(credential *models.WebAuthnCredential) error {
+	return database.DB.Create(credential).Error
+}
+
+func (r *passkeyRepository) FindByUser(userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *passkeyRepository) FindByCredentialID(credentialID []byte) (*models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	err := database.DB.Where("credential_id = ?", credentialID).First(&credential).Error
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *passkeyRepository) RecordUse(id uuid.UUID, previousCount, signCount int64, backupState bool) (bool, error) {
+	result := database.DB.Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, previousCount).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"backup_state": backupState,
+			"last_used_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *passkeyRepository) Delete(userID, id uuid.UUID) (bool, error) {
+	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebAuthnCredential{})
+	return result.RowsAffected > 0, result.Error
+}
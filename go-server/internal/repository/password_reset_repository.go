@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	// Create stores a new token and invalidates the user's earlier ones,
+	// so only the latest email works.
+	Create(token *models.PasswordResetToken) error
+	FindByTokenHash(tokenHash string) (*models.PasswordResetToken, error)
+	// Redeem uses the token, sets the user's password hash and revokes all
+	// of the user's sessions in one transaction. It returns false when the
+	// token was already used or has expired.
+	Redeem(token *models.PasswordResetToken, passwordHash string) (bool, error)
+}
+
+type passwordResetRepository struct{}
+
+func NewPasswordResetRepository() PasswordResetRepository {
+	return &passwordResetRepository{}
+}
+
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *passwordResetRepository) FindByTokenHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := database.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetRepository) Redeem(token *models.PasswordResetToken, passwordHash string) (bool, error) {
+	redeemed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// The conditional update makes a token single-use even when two
+		// resets race.
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		// Following the emailed link also proves the user owns the address.
+		err := tx.Model(&models.User{}).
+			Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{
+				"password":          passwordHash,
+				"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", token.UserID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+// ActiveSession is the live session of a sign-in, plus when that sign-in
+// happened.
+type ActiveSession struct {
+	models.Session `gorm:"embedded"`
+	SignedInAt     time.Time
+}
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindByID(id uuid.UUID) (*models.Session, error)
+	FindByTokenHash(tokenHash string) (*models.Session, error)
+	// Rotate marks current as used and stores next in one transaction. It
+	// reports false, storing nothing, when current was already rotated or
+	// revoked by a concurrent request.
+	Rotate(current, next *models.Session) (bool, error)
+	RevokeFamily(familyID uuid.UUID) error
+	// FindActiveByUser returns the unexpired, unrevoked session of each of
+	// the user's sign-ins, most recently used first.
+	FindActiveByUser(userID uuid.UUID) ([]ActiveSession, error)
+	RevokeUserFamily(userID, familyID uuid.UUID) (bool, error)
+	// RevokeOtherFamilies revokes all of the user's sessions outside
+	// keepFamilyID and returns how many sign-ins were ended.
+	RevokeOtherFamilies(userID, keepFamilyID uuid.UUID) (int64, error)
+}
+
+type sessionRepository struct{}
+
+func NewSessionRepository() SessionRepository {
+	return &sessionRepository{}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	return database.DB.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := database.DB.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindByTokenHash(tokenHash string) (*models.Session, error) {
+	var session models.Session
+	err := database.DB.Where("token_hash = ?", tokenHash).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) Rotate(current, next *models.Session) (bool, error) {
+	rotated := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Session{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{
+				"rotated_at":   now,
+				"last_used_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *sessionRepository) RevokeFamily(familyID uuid.UUID) error {
+	return database.DB.Model(&models.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) FindActiveByUser(userID uuid.UUID) ([]ActiveSession, error) {
+	var sessions []ActiveSession
+	err := database.DB.Model(&models.Session{}).
+		Select("sessions.*, (SELECT MIN(f.created_at) FROM sessions f WHERE f.family_id = sessions.family_id) AS signed_in_at").
+		Where("sessions.user_id = ? AND sessions.rotated_at IS NULL AND sessions.revoked_at IS NULL AND sessions.expires_at > ?", userID, time.Now()).
+		Order("sessions.last_used_at DESC").
+		Scan(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) RevokeUserFamily(userID, familyID uuid.UUID) (bool, error) {
+	result := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *sessionRepository) RevokeOtherFamilies(userID, keepFamilyID uuid.UUID) (int64, error) {
+	var families int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND family_id <> ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, keepFamilyID, time.Now()).
+			Count(&families).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+			Update("revoked_at", time.Now()).Error
+	})
+	return families, err
+}
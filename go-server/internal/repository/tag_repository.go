@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagWithCount is a tag plus the number of links carrying it.
+type TagWithCount struct {
+	models.Tag `gorm:"embedded"`
+	UsageCount int64
+}
+
+type TagRepository interface {
+	FindByID(id, userID uuid.UUID) (*models.Tag, error)
+	FindByName(userID uuid.UUID, name string) (*models.Tag, error)
+	FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error)
+	Update(tag *models.Tag) error
+	Merge(sourceID, targetID uuid.UUID) error
+	Autocomplete(userID uuid.UUID, prefix string, limit int) ([]TagWithCount, error)
+
+	AttachToLink(linkID uuid.UUID, tagIDs []uuid.UUID) error
+	DetachFromLink(linkID, tagID uuid.UUID) (bool, error)
+	FindByLink(linkID uuid.UUID) ([]models.Tag, error)
+	CountLinks(tagID uuid.UUID) (int64, error)
+	// FindNamesByLinks maps each link ID to its tag names, sorted by name.
+	FindNamesByLinks(linkIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+type tagRepository struct{}
+
+func NewTagRepository() TagRepository {
+	return &tagRepository{}
+}
+
+func (r *tagRepository) FindByID(id, userID uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByName(userID uuid.UUID, name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := database.DB.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that don't exist yet.
+func (r *tagRepository) FindOrCreate(userID uuid.UUID, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, models.Tag{UserID: userID, Name: name})
+	}
+
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoNothing: true,
+	}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []models.Tag
+	err = database.DB.Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (r *tagRepository) Update(tag *models.Tag) error {
+	return database.DB.Save(tag).Error
+}
+
+// Merge moves every link from the source tag to the target tag and deletes
+// the source tag.
+func (r *tagRepository) Merge(sourceID, targetID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO link_tags (link_id, tag_id, created_at)
+			SELECT link_id, ?, created_at FROM link_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", sourceID).Delete(&models.Tag{}).Error
+	})
+}
+
+// Autocomplete returns the user's tags starting with prefix, most used
+// first.
+func (r *tagRepository) Autocomplete(userID uuid.UUID, prefix string, limit int) ([]TagWithCount, error) {
+	query := database.DB.Model(&models.Tag{}).
+		Select("tags.*, COUNT(link_tags.link_id) AS usage_count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id")
+
+	if prefix != "" {
+		query = query.Where("tags.name LIKE ?", escapeLike(prefix)+"%")
+	}
+
+	var tags []TagWithCount
+	err := query.Order("usage_count DESC, tags.name ASC").Limit(limit).Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) AttachToLink(linkID uuid.UUID, tagIDs []uuid.UUID) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	linkTags := make([]models.LinkTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		linkTags = append(linkTags, models.LinkTag{LinkID: linkID, TagID: tagID})
+	}
+
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&linkTags).Error
+}
+
+func (r *tagRepository) DetachFromLink(linkID, tagID uuid.UUID) (bool, error) {
+	result := database.DB.Where("link_id = ? AND tag_id = ?", linkID, tagID).Delete(&models.LinkTag{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *tagRepository) FindByLink(linkID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := database.DB.
+		Joins("JOIN link_tags ON link_tags.tag_id = tags.id").
+		Where("link_tags.link_id = ?", linkID).
+		Order("tags.name").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) CountLinks(tagID uuid.UUID) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.LinkTag{}).Where("tag_id = ?", tagID).Count(&count).Error
+	return count, err
+}
+
+func (r *tagRepository) FindNamesByLinks(linkIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	names := make(map[uuid.UUID][]string, len(linkIDs))
+	if len(linkIDs) == 0 {
+		return names, nil
+	}
+
+	var rows []struct {
+		LinkID uuid.UUID
+		Name   string
+	}
+	err := database.DB.Model(&models.LinkTag{}).
+		Select("link_tags.link_id, tags.name").
+		Joins("JOIN tags ON tags.id = link_tags.tag_id").
+		Where("link_tags.link_id IN ?", linkIDs).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		names[row.LinkID] = append(names[row.LinkID], row.Name)
+	}
+	return names, nil
+}
+
+// escapeLike escapes the LIKE wildcards in user input.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -10,6 +10,7 @@ type UserRepository interface {
 	Create(user *models.User) error
 	FindByID(id uuid.UUID) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
+	FindByOAuth(provider, oauthID string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uuid.UUID) error
 }
@@ -42,6 +43,15 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) FindByOAuth(provider, oauthID string) (*models.User, error) {
+	var user models.User
+	err := database.DB.Where("oauth_provider = ? AND oauth_id = ?", provider, oauthID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Update(user *models.User) error {
 	return database.DB.Save(user).Error
 }
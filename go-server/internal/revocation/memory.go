@@ -0,0 +1,46 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore returns a process-local Store, for tests and single
+// instance setups.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Revoke(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, expiry := range s.revoked {
+		if !expiry.After(now) {
+			delete(s.revoked, id)
+		}
+	}
+
+	if expiresAt.After(now) {
+		s.revoked[jti] = expiresAt
+	}
+	return nil
+}
+
+func (s *memoryStore) IsRevoked(jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiry, ok := s.revoked[jti]
+	return ok && expiry.After(s.now()), nil
+}
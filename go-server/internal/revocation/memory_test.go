@@ -0,0 +1,55 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreRevoke(t *testing.T) {
+	store := NewMemoryStore()
+
+	revoked, err := store.IsRevoked("token-1")
+	if err != nil || revoked {
+		t.Fatalf("IsRevoked before Revoke = %v, %v; want false, nil", revoked, err)
+	}
+
+	if err := store.Revoke("token-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	revoked, err = store.IsRevoked("token-1")
+	if err != nil || !revoked {
+		t.Fatalf("IsRevoked after Revoke = %v, %v; want true, nil", revoked, err)
+	}
+
+	if revoked, _ := store.IsRevoked("token-2"); revoked {
+		t.Fatal("unrelated token reported as revoked")
+	}
+}
+
+func TestMemoryStoreForgetsExpiredTokens(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{revoked: make(map[string]time.Time), now: func() time.Time { return now }}
+
+	if err := store.Revoke("expired", now.Add(-time.Second)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if revoked, _ := store.IsRevoked("expired"); revoked {
+		t.Fatal("already expired token should not be stored")
+	}
+
+	if err := store.Revoke("short", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if revoked, _ := store.IsRevoked("short"); revoked {
+		t.Fatal("token should be forgotten once it expires")
+	}
+
+	if err := store.Revoke("other", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, ok := store.revoked["short"]; ok {
+		t.Fatal("expired entry was not pruned")
+	}
+}
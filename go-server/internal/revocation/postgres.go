@@ -0,0 +1,44 @@
+package revocation
+
+import (
+	"time"
+
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore returns a Store backed by the revoked_tokens table, shared
+// by every server instance.
+func NewPostgresStore(db *gorm.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Revoke(jti string, expiresAt time.Time) error {
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Entries are useless once the token has expired; drop them here
+		// rather than in a separate job.
+		if err := tx.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+	})
+}
+
+func (s *postgresStore) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.RevokedToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
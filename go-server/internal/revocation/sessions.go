@@ -0,0 +1,95 @@
+package revocation
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// SessionCacheTTL is how long a session is remembered as active, and
+	// so how long its access tokens may keep working after it is revoked.
+	// Revoked sessions never come back, so they are remembered until the
+	// cache is pruned.
+	SessionCacheTTL = 5 * time.Second
+
+	// maxCachedSessions bounds the cache; past it, stale entries are
+	// pruned and new ones are not kept until there is room.
+	maxCachedSessions = 100000
+)
+
+type sessionStatus struct {
+	revoked   bool
+	checkedAt time.Time
+}
+
+type sessionStore struct {
+	lookup func(sessionID uuid.UUID) (bool, error)
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]sessionStatus
+}
+
+// NewPostgresSessionStore returns a SessionStore that reads the sessions
+// table, caching each answer for SessionCacheTTL so most requests do not
+// query it. A session that no longer exists counts as revoked.
+func NewPostgresSessionStore(db *gorm.DB) SessionStore {
+	return newSessionStore(func(sessionID uuid.UUID) (bool, error) {
+		var session models.Session
+		err := db.Select("revoked_at").Where("id = ?", sessionID).First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return session.RevokedAt != nil, nil
+	}, SessionCacheTTL)
+}
+
+func newSessionStore(lookup func(uuid.UUID) (bool, error), ttl time.Duration) *sessionStore {
+	return &sessionStore{
+		lookup:   lookup,
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[uuid.UUID]sessionStatus),
+	}
+}
+
+func (s *sessionStore) IsSessionRevoked(sessionID uuid.UUID) (bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	status, ok := s.sessions[sessionID]
+	s.mu.Unlock()
+	if ok && (status.revoked || now.Sub(status.checkedAt) < s.ttl) {
+		return status.revoked, nil
+	}
+
+	revoked, err := s.lookup(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Entries are only needed while access tokens for the session may
+	// still be in use; forgetting one just costs another lookup.
+	if len(s.sessions) >= maxCachedSessions {
+		for id, cached := range s.sessions {
+			if now.Sub(cached.checkedAt) >= s.ttl {
+				delete(s.sessions, id)
+			}
+		}
+	}
+	if len(s.sessions) < maxCachedSessions {
+		s.sessions[sessionID] = sessionStatus{revoked: revoked, checkedAt: now}
+	}
+	return revoked, nil
+}
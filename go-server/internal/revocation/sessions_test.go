@@ -0,0 +1,43 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSessionStoreCachesActiveSessionsBriefly(t *testing.T) {
+	now := time.Now()
+	revoked := map[uuid.UUID]bool{}
+	lookups := 0
+	store := newSessionStore(func(id uuid.UUID) (bool, error) {
+		lookups++
+		return revoked[id], nil
+	}, time.Second)
+	store.now = func() time.Time { return now }
+
+	session := uuid.New()
+	for i := 0; i < 3; i++ {
+		if got, err := store.IsSessionRevoked(session); err != nil || got {
+			t.Fatalf("IsSessionRevoked = %v, %v; want false, nil", got, err)
+		}
+	}
+	if lookups != 1 {
+		t.Fatalf("%d lookups for one active session, want 1", lookups)
+	}
+
+	revoked[session] = true
+	now = now.Add(2 * time.Second)
+	if got, _ := store.IsSessionRevoked(session); !got {
+		t.Fatal("revoked session still active after the cache expired")
+	}
+
+	now = now.Add(time.Hour)
+	if got, _ := store.IsSessionRevoked(session); !got {
+		t.Fatal("revoked session forgotten")
+	}
+	if lookups != 2 {
+		t.Fatalf("%d lookups, want 2: a revoked session needs no second check", lookups)
+	}
+}
@@ -0,0 +1,23 @@
+// Package revocation records JWT IDs that must no longer be accepted even
+// though their signature and expiry are still valid.
+package revocation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Store interface {
+	// Revoke blocks the token ID until expiresAt, after which the token is
+	// rejected for having expired anyway.
+	Revoke(jti string, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+}
+
+// SessionStore reports whether the sign-in session an access token was
+// issued for has ended, so signing a device out also stops the access
+// tokens it still holds.
+type SessionStore interface {
+	IsSessionRevoked(sessionID uuid.UUID) (bool, error)
+}
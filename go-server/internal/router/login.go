@@ -0,0 +1,53 @@
+package router
+
+import (
+	"log"
+	"time"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/database"
+	"github.com/video-mobile-app/go-server/internal/lockout"
+)
+
+// Failures past the free ones wait 1s, 2s, 4s... up to 30s before the next
+// try. Client IPs get more free attempts, as many users can share one
+// behind NAT.
+const (
+	accountFreeLoginAttempts = 3
+	ipFreeLoginAttempts      = 20
+	loginBackoffBase         = time.Second
+	loginBackoffMax          = 30 * time.Second
+)
+
+// newLoginTracker builds the failed sign-in tracker on the configured
+// store.
+func newLoginTracker(cfg *config.Config) *lockout.Tracker {
+	var store lockout.Store
+	switch cfg.Login.Store {
+	case "memory":
+		store = lockout.NewMemoryStore()
+	case "postgres":
+		store = lockout.NewPostgresStore(database.DB)
+	default:
+		log.Printf("Unknown LOGIN_ATTEMPT_STORE %q, using postgres", cfg.Login.Store)
+		store = lockout.NewPostgresStore(database.DB)
+	}
+
+	return lockout.NewTracker(store, lockout.Options{
+		Window: cfg.Login.FailureWindow,
+		Account: lockout.Policy{
+			FreeAttempts:    accountFreeLoginAttempts,
+			BaseDelay:       loginBackoffBase,
+			MaxDelay:        loginBackoffMax,
+			LockoutAfter:    cfg.Login.AccountLockoutAfter,
+			LockoutDuration: cfg.Login.LockoutDuration,
+		},
+		IP: lockout.Policy{
+			FreeAttempts:    ipFreeLoginAttempts,
+			BaseDelay:       loginBackoffBase,
+			MaxDelay:        loginBackoffMax,
+			LockoutAfter:    cfg.Login.IPLockoutAfter,
+			LockoutDuration: cfg.Login.LockoutDuration,
+		},
+	})
+}
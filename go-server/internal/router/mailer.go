@@ -0,0 +1,46 @@
+package router
+
+import (
+	"log"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/mailer"
+)
+
+// newMailer builds the configured mailer and the bundled email templates.
+// Outside production, a mailer that cannot be set up is logged and replaced
+// by the log mailer so the rest of the API keeps working; in production the
+// server refuses to start instead, since no email would reach users. The
+// log mailer only prints message bodies, with their links, in development.
+func newMailer(cfg *config.Config) (mailer.Mailer, *mailer.Renderer) {
+	templates := mailer.NewRenderer(mailer.DefaultTemplates(), cfg.Mail.DefaultLocale)
+	development := cfg.Server.Env == "development"
+
+	mail, err := mailer.New(mailer.Options{
+		Mode: cfg.Mail.Mode,
+		From: cfg.Mail.From,
+		SMTP: mailer.SMTPOptions{
+			Host:        cfg.Mail.SMTPHost,
+			Port:        cfg.Mail.SMTPPort,
+			Username:    cfg.Mail.SMTPUsername,
+			Password:    cfg.Mail.SMTPPassword,
+			ImplicitTLS: cfg.Mail.SMTPPort == 465,
+		},
+		OutboxDir: cfg.Mail.OutboxDir,
+		LogBodies: development,
+	})
+	if err != nil {
+		if cfg.Server.Env == "production" {
+			log.Fatalf("Failed to set up email delivery: %v", err)
+		}
+		log.Printf("Email delivery disabled, logging mail instead: %v", err)
+		logMailer := mailer.NewLogMailer(nil)
+		logMailer.LogBodies = development
+		mail = logMailer
+	}
+	if _, ok := mail.(*mailer.LogMailer); ok && cfg.Server.Env == "production" {
+		log.Fatalf("Failed to set up email delivery: MAIL_MODE must be smtp or outbox in production")
+	}
+
+	return mail, templates
+}
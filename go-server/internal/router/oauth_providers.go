@@ -0,0 +1,56 @@
+package router
+
+import (
+	"log"
+	"strings"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/oauth"
+	"github.com/video-mobile-app/go-server/internal/service"
+)
+
+// oauthProviders builds the browser sign-in providers that have
+// credentials configured. A provider that cannot be set up is logged and
+// left out rather than stopping the server.
+func oauthProviders(cfg *config.Config) []service.IdentityProvider {
+	var providers []service.IdentityProvider
+
+	callbackURL := func(provider string) string {
+		return strings.TrimRight(cfg.OAuth.CallbackBaseURL, "/") + "/" + provider + "/callback"
+	}
+
+	if len(cfg.Google.ClientIDs) > 0 && cfg.OAuth.GoogleClientSecret != "" {
+		providers = append(providers, oauth.NewOIDCProvider(oauth.GoogleConfig(oauth.Client{
+			ID:          cfg.Google.ClientIDs[0],
+			Secret:      oauth.StaticSecret(cfg.OAuth.GoogleClientSecret),
+			RedirectURL: callbackURL(constants.OAuthProviderGoogle),
+		})))
+	}
+
+	if cfg.OAuth.GitHubClientID != "" && cfg.OAuth.GitHubClientSecret != "" {
+		providers = append(providers, oauth.NewGitHubProvider(oauth.Client{
+			ID:          cfg.OAuth.GitHubClientID,
+			Secret:      oauth.StaticSecret(cfg.OAuth.GitHubClientSecret),
+			RedirectURL: callbackURL(constants.OAuthProviderGitHub),
+		}, oauth.Endpoints{}, ""))
+	}
+
+	if cfg.OAuth.AppleClientID != "" {
+		// The .p8 key usually arrives through the environment with escaped
+		// newlines.
+		key := strings.ReplaceAll(cfg.OAuth.ApplePrivateKey, `\n`, "\n")
+		secret, err := oauth.AppleClientSecret(cfg.OAuth.AppleTeamID, cfg.OAuth.AppleClientID, cfg.OAuth.AppleKeyID, []byte(key))
+		if err != nil {
+			log.Printf("Apple sign-in disabled: %v", err)
+		} else {
+			providers = append(providers, oauth.NewOIDCProvider(oauth.AppleConfig(oauth.Client{
+				ID:          cfg.OAuth.AppleClientID,
+				Secret:      secret,
+				RedirectURL: callbackURL(constants.OAuthProviderApple),
+			})))
+		}
+	}
+
+	return providers
+}
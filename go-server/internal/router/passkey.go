@@ -0,0 +1,25 @@
+package router
+
+import (
+	"log"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/passkey"
+	"github.com/video-mobile-app/go-server/internal/service"
+)
+
+// newRelyingParty sets up passkeys for the configured origins. Passkeys
+// stay off, and the rest of the API keeps working, when that fails.
+func newRelyingParty(cfg *config.Config) *passkey.RelyingParty {
+	relyingParty, err := passkey.New(passkey.Config{
+		RPID:    cfg.WebAuthn.RPID,
+		RPName:  cfg.WebAuthn.RPName,
+		Origins: cfg.WebAuthn.Origins,
+		Timeout: service.PasskeyCeremonyTTL,
+	})
+	if err != nil {
+		log.Printf("Passkeys disabled: %v", err)
+		return nil
+	}
+	return relyingParty
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustProxies makes c.ClientIP believe X-Forwarded-For only from the
+// given proxies. Gin trusts every peer by default, which would let any
+// client choose the IP that rate limits and sign-in lockouts key on. A
+// list that does not parse is logged and no proxy is trusted.
+func trustProxies(r *gin.Engine, proxies []string) {
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Printf("Trusting no proxies, invalid TRUSTED_PROXIES: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+}
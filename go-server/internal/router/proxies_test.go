@@ -0,0 +1,73 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/middleware"
+	"github.com/video-mobile-app/go-server/internal/ratelimit"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// limitedEngine allows one request a minute per client IP.
+func limitedEngine(proxies []string) *gin.Engine {
+	r := gin.New()
+	trustProxies(r, proxies)
+	r.GET("/limited", middleware.RateLimit(ratelimit.NewMemoryStore(), "test", []ratelimit.Rule{
+		{Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}},
+	}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func getFrom(r http.Handler, peer, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = peer + ":1234"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestSpoofedForwardedForDoesNotResetRateLimit(t *testing.T) {
+	r := limitedEngine(nil)
+
+	if code := getFrom(r, "203.0.113.7", "198.51.100.1"); code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", code)
+	}
+	if code := getFrom(r, "203.0.113.7", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("request with a new X-Forwarded-For: status %d, want 429", code)
+	}
+}
+
+func TestTrustedProxyForwardsClientIP(t *testing.T) {
+	r := limitedEngine([]string{"10.0.0.0/8"})
+
+	if code := getFrom(r, "10.0.0.2", "198.51.100.1"); code != http.StatusNoContent {
+		t.Fatalf("first client: status %d", code)
+	}
+	if code := getFrom(r, "10.0.0.2", "198.51.100.2"); code != http.StatusNoContent {
+		t.Fatalf("second client behind the proxy: status %d", code)
+	}
+	if code := getFrom(r, "10.0.0.3", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("first client again through another proxy: status %d, want 429", code)
+	}
+}
+
+func TestInvalidTrustedProxiesTrustsNone(t *testing.T) {
+	r := limitedEngine([]string{"not-an-address"})
+
+	getFrom(r, "203.0.113.7", "198.51.100.1")
+	if code := getFrom(r, "203.0.113.7", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("request with a new X-Forwarded-For: status %d, want 429", code)
+	}
+}
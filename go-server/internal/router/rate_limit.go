@@ -0,0 +1,56 @@
+package router
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/middleware"
+	"github.com/video-mobile-app/go-server/internal/ratelimit"
+)
+
+// newRateLimiter returns a function building the rate limit middleware
+// for a route group, from the configured rules and store. Groups without
+// rules, and every group when rate limiting is off or its rules do not
+// parse, are not limited.
+func newRateLimiter(cfg *config.Config) func(group string) gin.HandlerFunc {
+	unlimited := func(c *gin.Context) { c.Next() }
+	if !cfg.RateLimit.Enabled {
+		return func(string) gin.HandlerFunc { return unlimited }
+	}
+
+	policy, err := ratelimit.ParsePolicy(cfg.RateLimit.Rules)
+	if err != nil {
+		log.Printf("Rate limiting disabled: %v", err)
+		return func(string) gin.HandlerFunc { return unlimited }
+	}
+
+	store := newRateLimitStore(cfg)
+	return func(group string) gin.HandlerFunc {
+		rules := policy[group]
+		if len(rules) == 0 {
+			return unlimited
+		}
+		return middleware.RateLimit(store, group, rules)
+	}
+}
+
+// newRateLimitStore connects to Redis when configured. A Redis URL that
+// does not parse is logged and replaced by the in-process store.
+func newRateLimitStore(cfg *config.Config) ratelimit.Store {
+	switch cfg.RateLimit.Store {
+	case "redis":
+		options, err := redis.ParseURL(cfg.Redis.URL)
+		if err != nil {
+			log.Printf("Rate limits kept in memory, invalid REDIS_URL: %v", err)
+			return ratelimit.NewMemoryStore()
+		}
+		return ratelimit.NewRedisStore(redis.NewClient(options))
+	case "memory":
+		return ratelimit.NewMemoryStore()
+	default:
+		log.Printf("Unknown RATE_LIMIT_STORE %q, keeping rate limits in memory", cfg.RateLimit.Store)
+		return ratelimit.NewMemoryStore()
+	}
+}
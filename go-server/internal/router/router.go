@@ -2,10 +2,17 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/database"
 	"github.com/video-mobile-app/go-server/internal/handler"
+	"github.com/video-mobile-app/go-server/internal/idtoken"
+	"github.com/video-mobile-app/go-server/internal/metadata"
 	"github.com/video-mobile-app/go-server/internal/middleware"
+	"github.com/video-mobile-app/go-server/internal/rbac"
 	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/revocation"
 	"github.com/video-mobile-app/go-server/internal/service"
+	"github.com/video-mobile-app/go-server/internal/utils"
 )
 
 func SetupRouter() *gin.Engine {
@@ -14,24 +21,154 @@ func SetupRouter() *gin.Engine {
 	}
 
 	r := gin.New()
+	trustProxies(r, config.AppConfig.Server.TrustedProxies)
 
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORSMiddleware())
 
+	utils.SetRevocationStore(revocation.NewPostgresStore(database.DB))
+	utils.SetSessionStore(revocation.NewPostgresSessionStore(database.DB))
+
 	userRepo := repository.NewUserRepository()
-	authService := service.NewAuthService(userRepo)
+	sessionRepo := repository.NewSessionRepository()
+	identityRepo := repository.NewIdentityRepository()
+	mail, mailTemplates := newMailer(config.AppConfig)
+
+	emailVerificationRepo := repository.NewEmailVerificationRepository()
+	emailVerificationService := service.NewEmailVerificationService(userRepo, sessionRepo, emailVerificationRepo, mail, mailTemplates)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService)
+	requireVerifiedEmail := middleware.RequireVerifiedEmail(emailVerificationService)
+
+	mfaRepo := repository.NewMFARepository()
+	loginTracker := newLoginTracker(config.AppConfig)
+	mfaService := service.NewMFAService(userRepo, mfaRepo, loginTracker)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+
+	passkeyRepo := repository.NewPasskeyRepository()
+	passkeyService := service.NewPasskeyService(userRepo, passkeyRepo, newRelyingParty(config.AppConfig))
+	passkeyHandler := handler.NewPasskeyHandler(passkeyService)
+
+	magicLinkRepo := repository.NewMagicLinkRepository()
+	magicLinkService := service.NewMagicLinkService(userRepo, emailVerificationService, magicLinkRepo, mail, mailTemplates)
+
+	var googleVerifier service.IDTokenVerifier
+	if clientIDs := config.AppConfig.Google.ClientIDs; len(clientIDs) > 0 {
+		googleVerifier = idtoken.NewGoogleVerifier(clientIDs, nil)
+	}
+	authService := service.NewAuthService(userRepo, sessionRepo, identityRepo, emailVerificationService, mfaService, passkeyService, magicLinkService, loginTracker, googleVerifier, oauthProviders(config.AppConfig)...)
 	authHandler := handler.NewAuthHandler(authService)
 
+	passwordResetRepo := repository.NewPasswordResetRepository()
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, mail, mailTemplates)
+	passwordHandler := handler.NewPasswordHandler(passwordService)
+
+	linkRepo := repository.NewLinkRepository()
+	tagRepo := repository.NewTagRepository()
+	metadataFetcher := metadata.NewFetcher(metadata.Options{
+		Timeout:      config.AppConfig.Metadata.FetchTimeout,
+		MaxBodyBytes: config.AppConfig.Metadata.MaxBodyBytes,
+	})
+	linkService := service.NewLinkService(linkRepo, tagRepo, metadataFetcher)
+	linkHandler := handler.NewLinkHandler(linkService)
+
+	collectionRepo := repository.NewCollectionRepository()
+	collectionService := service.NewCollectionService(collectionRepo, linkRepo, tagRepo)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
+
+	collectionShareRepo := repository.NewCollectionShareRepository()
+	collectionShareService := service.NewCollectionShareService(collectionShareRepo, collectionRepo, tagRepo, loginTracker)
+	collectionShareHandler := handler.NewCollectionShareHandler(collectionShareService)
+
+	tagService := service.NewTagService(tagRepo, linkRepo)
+	tagHandler := handler.NewTagHandler(tagService)
+
+	adminService := service.NewAdminService(userRepo, sessionRepo)
+	adminHandler := handler.NewAdminHandler(adminService)
+
+	rateLimit := newRateLimiter(config.AppConfig)
+	limitEmails := rateLimit("auth.email")
+
 	api := r.Group("/api")
 	{
-		auth := api.Group("/auth")
+		auth := api.Group("/auth", rateLimit("auth"))
 		{
 			auth.POST("/signup", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
+			auth.GET("/mfa", middleware.JWTAuthMiddleware(), mfaHandler.Status)
+			auth.POST("/mfa/totp/setup", middleware.JWTAuthMiddleware(), mfaHandler.Setup)
+			auth.POST("/mfa/totp/confirm", middleware.JWTAuthMiddleware(), mfaHandler.Confirm)
+			auth.POST("/mfa/totp/disable", middleware.JWTAuthMiddleware(), mfaHandler.Disable)
+			auth.POST("/passkeys/login/options", authHandler.PasskeyLoginOptions)
+			auth.POST("/passkeys/login", authHandler.PasskeyLogin)
+			auth.GET("/passkeys", middleware.JWTAuthMiddleware(), passkeyHandler.List)
+			auth.POST("/passkeys/register/options", middleware.JWTAuthMiddleware(), passkeyHandler.RegistrationOptions)
+			auth.POST("/passkeys/register", middleware.JWTAuthMiddleware(), passkeyHandler.Register)
+			auth.DELETE("/passkeys/:id", middleware.JWTAuthMiddleware(), passkeyHandler.Delete)
+			auth.POST("/magic-link", limitEmails, authHandler.RequestMagicLink)
+			auth.POST("/magic-link/consume", authHandler.MagicLinkLogin)
+			auth.POST("/google", authHandler.GoogleLogin)
+			auth.GET("/oauth/:provider/start", authHandler.OAuthStart)
+			auth.GET("/oauth/:provider/callback", authHandler.OAuthCallback)
+			auth.POST("/oauth/:provider/callback", authHandler.OAuthCallback)
 			auth.POST("/token/refresh", authHandler.RefreshToken)
+			auth.POST("/password/forgot", limitEmails, passwordHandler.Forgot)
+			auth.POST("/password/reset", passwordHandler.Reset)
+			auth.POST("/email/verify", emailVerificationHandler.Verify)
+			auth.POST("/email/resend", middleware.JWTAuthMiddleware(), limitEmails, emailVerificationHandler.Resend)
 			auth.POST("/logout", middleware.JWTAuthMiddleware(), authHandler.Logout)
 			auth.GET("/me", middleware.JWTAuthMiddleware(), authHandler.GetCurrentUser)
+			auth.PUT("/profile", middleware.JWTAuthMiddleware(), authHandler.UpdateProfile)
+			auth.PUT("/password", middleware.JWTAuthMiddleware(), authHandler.ChangePassword)
+			auth.GET("/sessions", middleware.JWTAuthMiddleware(), authHandler.ListSessions)
+			auth.DELETE("/sessions", middleware.JWTAuthMiddleware(), authHandler.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", middleware.JWTAuthMiddleware(), authHandler.RevokeSession)
+		}
+
+		links := api.Group("/links", middleware.JWTAuthMiddleware(), rateLimit("links"))
+		{
+			links.POST("", linkHandler.Create)
+			links.GET("", linkHandler.List)
+			links.GET("/search", linkHandler.Search)
+			links.POST("/:id/tags", tagHandler.AddToLink)
+			links.DELETE("/:id/tags/:tagId", tagHandler.RemoveFromLink)
+		}
+
+		tags := api.Group("/tags", middleware.JWTAuthMiddleware(), rateLimit("tags"))
+		{
+			tags.GET("", tagHandler.Autocomplete)
+			tags.PATCH("/:id", tagHandler.Rename)
+		}
+
+		collections := api.Group("/collections", middleware.JWTAuthMiddleware(), rateLimit("collections"))
+		{
+			collections.POST("", collectionHandler.Create)
+			collections.GET("", collectionHandler.List)
+			collections.GET("/:id", collectionHandler.Get)
+			collections.PATCH("/:id", collectionHandler.Update)
+			collections.DELETE("/:id", collectionHandler.Delete)
+			collections.GET("/:id/items", collectionHandler.ListItems)
+			collections.POST("/:id/items", collectionHandler.AddItem)
+			collections.DELETE("/:id/items/:linkId", collectionHandler.RemoveItem)
+			collections.PUT("/:id/items/:linkId/position", collectionHandler.MoveItem)
+			collections.POST("/:id/shares", requireVerifiedEmail, collectionShareHandler.Create)
+			collections.GET("/:id/shares", collectionShareHandler.List)
+			collections.DELETE("/:id/shares/:shareId", collectionShareHandler.Revoke)
+		}
+
+		admin := api.Group("/admin", middleware.JWTAuthMiddleware(), rateLimit("admin"))
+		{
+			admin.GET("/users/:id", middleware.RequirePermission(rbac.PermissionUsersRead), adminHandler.GetUser)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(rbac.PermissionUsersManageRoles), adminHandler.SetRole)
+		}
+
+		// Public, read-only collection views; the share token stands in for
+		// a user session.
+		shared := api.Group("/shared/:token", rateLimit("shared"), middleware.ShareTokenMiddleware(collectionShareService))
+		{
+			shared.GET("", collectionShareHandler.GetShared)
+			shared.GET("/items", collectionShareHandler.ListSharedItems)
 		}
 	}
 
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/rbac"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrCannotChangeOwnRole = errors.New("you cannot change your own role")
+)
+
+// AdminService manages other users' accounts. Callers check permissions
+// with middleware.RequirePermission before reaching it.
+type AdminService interface {
+	GetUser(userID uuid.UUID) (*dto.UserDetailResponse, error)
+	SetRole(actorID, userID uuid.UUID, req *dto.UpdateUserRoleRequest) (*dto.UserDetailResponse, error)
+}
+
+type adminService struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+}
+
+func NewAdminService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) AdminService {
+	return &adminService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+func (s *adminService) GetUser(userID uuid.UUID) (*dto.UserDetailResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	data := mapUserToDTO(user)
+	return &dto.UserDetailResponse{
+		Success: true,
+		Message: "User retrieved successfully",
+		Data:    &data,
+	}, nil
+}
+
+// SetRole changes a user's role. A promotion applies to their tokens from
+// the next refresh; a role that takes permissions away signs them out
+// everywhere, since their tokens still carry the old role. Nobody may
+// change their own role, so the last super admin cannot demote themselves
+// by accident.
+func (s *adminService) SetRole(actorID, userID uuid.UUID, req *dto.UpdateUserRoleRequest) (*dto.UserDetailResponse, error) {
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if user.Role != req.Role {
+		demoted := !rbac.Includes(req.Role, user.Role)
+		user.Role = req.Role
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+		if demoted {
+			if _, err := s.sessionRepo.RevokeOtherFamilies(user.ID, uuid.Nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data := mapUserToDTO(user)
+	return &dto.UserDetailResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Data:    &data,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+)
+
+// oneUserRepo stores a single user in memory.
+type oneUserRepo struct {
+	repository.UserRepository
+	user *models.User
+}
+
+func (r *oneUserRepo) FindByID(uuid.UUID) (*models.User, error) {
+	user := *r.user
+	return &user, nil
+}
+
+func (r *oneUserRepo) Update(user *models.User) error {
+	r.user = user
+	return nil
+}
+
+// signOutRecorder records whose sessions were revoked.
+type signOutRecorder struct {
+	repository.SessionRepository
+	signedOut []uuid.UUID
+}
+
+func (r *signOutRecorder) RevokeOtherFamilies(userID, _ uuid.UUID) (int64, error) {
+	r.signedOut = append(r.signedOut, userID)
+	return 1, nil
+}
+
+func TestSetRoleSignsOutDemotedUsers(t *testing.T) {
+	for _, tt := range []struct {
+		from, to  string
+		signedOut bool
+	}{
+		{constants.RoleUser, constants.RoleAdmin, false},
+		{constants.RoleAdmin, constants.RoleSuperAdmin, false},
+		{constants.RoleAdmin, constants.RoleAdmin, false},
+		{constants.RoleAdmin, constants.RoleUser, true},
+		{constants.RoleSuperAdmin, constants.RoleAdmin, true},
+	} {
+		users := &oneUserRepo{user: &models.User{ID: uuid.New(), Role: tt.from}}
+		sessions := &signOutRecorder{}
+		admin := NewAdminService(users, sessions)
+
+		if _, err := admin.SetRole(uuid.New(), users.user.ID, &dto.UpdateUserRoleRequest{Role: tt.to}); err != nil {
+			t.Fatalf("%s to %s: %v", tt.from, tt.to, err)
+		}
+		if users.user.Role != tt.to {
+			t.Errorf("%s to %s: stored role %q", tt.from, tt.to, users.user.Role)
+		}
+		if got := len(sessions.signedOut) > 0; got != tt.signedOut {
+			t.Errorf("%s to %s: signed out = %v, want %v", tt.from, tt.to, got, tt.signedOut)
+		}
+	}
+}
@@ -1,36 +1,153 @@
 package service
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
 	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/idtoken"
 	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/oauth"
 	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/useragent"
 	"github.com/video-mobile-app/go-server/internal/utils"
 	"gorm.io/gorm"
 )
 
+const maxUserAgentLength = 512
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrSessionNotFound     = errors.New("session not found")
+
+	ErrGoogleSignInDisabled = errors.New("Google sign-in is not configured")
+	ErrInvalidGoogleToken   = errors.New("invalid Google token")
+	ErrEmailNotVerified     = errors.New("a verified email address is required to sign in")
+	ErrOAuthAccountConflict = errors.New("this account is linked to a different account from this provider")
+	ErrUnknownProvider      = errors.New("unknown sign-in provider")
+	ErrInvalidOAuthState    = errors.New("sign-in request expired or was tampered with")
+	ErrOAuthExchangeFailed  = errors.New("sign-in with the provider failed")
+	ErrIncorrectPassword    = errors.New("current password is incorrect")
+	ErrNoPasswordSet        = errors.New("this account has no password; use forgot password to set one")
+)
+
+// OAuthStateTTL bounds how long a user may spend at the provider.
+const OAuthStateTTL = 10 * time.Minute
+
+// IdentityProvider is an external sign-in provider using the OAuth 2.0
+// authorization code flow with PKCE.
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(req *oauth.AuthRequest) string
+	// Exchange redeems an authorization code and describes the signed-in
+	// account.
+	Exchange(ctx context.Context, code string, req *oauth.AuthRequest) (*oauth.Identity, error)
+}
+
+// IDTokenVerifier checks an OpenID Connect ID token and returns its claims.
+type IDTokenVerifier interface {
+	Verify(ctx context.Context, rawToken string) (*idtoken.Claims, error)
+}
+
+// LoginLimiter slows down password guessing by account and client IP.
+type LoginLimiter interface {
+	// Begin returns how long a sign-in to account from ip must wait, or
+	// zero when it may go ahead. An attempt that goes ahead counts as a
+	// failure until Succeed, so parallel attempts cannot all pass.
+	Begin(account, ip string) (time.Duration, error)
+	Fail(account, ip string) error
+	Succeed(account, ip string) error
+}
+
+// ClientInfo describes the device a session is issued to.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	// Locale is the client's Accept-Language, used for emails.
+	Locale string
+}
+
 type AuthService interface {
-	Register(req *dto.RegisterRequest) (*dto.AuthResponse, string, string, error)
-	Login(req *dto.LoginRequest) (*dto.AuthResponse, string, string, error)
-	RefreshToken(refreshToken string) (string, string, error)
+	Register(req *dto.RegisterRequest, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	// Login checks the password. When two-factor authentication is on it
+	// returns an MFA token and no session tokens; see VerifyMFA.
+	// It returns a *ThrottledError while the account or client IP has
+	// failed too often.
+	Login(req *dto.LoginRequest, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	VerifyMFA(req *dto.MFAVerifyRequest, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	PasskeyLoginOptions() (*dto.PasskeyOptionsResponse, error)
+	// PasskeyLogin signs in with a passkey. The passkey checked the user's
+	// PIN or biometrics, so it skips the second factor.
+	PasskeyLogin(req *dto.PasskeyLoginRequest, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	// RequestMagicLink emails a sign-in link; see MagicLinkService.Request.
+	RequestMagicLink(req *dto.MagicLinkRequest, locale string) (*dto.TokenResponse, string, error)
+	// MagicLinkLogin signs in with an emailed link and the nonce issued
+	// with it. The link proves only the email address, so two-factor
+	// authentication still applies.
+	MagicLinkLogin(req *dto.MagicLinkConsumeRequest, nonce string, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	GoogleLogin(ctx context.Context, req *dto.GoogleAuthRequest, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	OAuthStart(provider string) (string, string, error)
+	OAuthCallback(ctx context.Context, provider, code, state, stateToken string, client ClientInfo) (*dto.AuthResponse, string, string, error)
+	RefreshToken(refreshToken string, client ClientInfo) (string, string, error)
+	Logout(claims *utils.Claims, refreshToken string) error
+	ListSessions(claims *utils.Claims) (*dto.SessionListResponse, error)
+	RevokeSession(claims *utils.Claims, sessionID uuid.UUID) error
+	RevokeOtherSessions(claims *utils.Claims) (*dto.RevokeSessionsResponse, error)
 	ValidateUser(userID uuid.UUID) (*models.User, error)
+	UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (*dto.AuthResponse, error)
+	// ChangePassword replaces the password after checking the current one
+	// and signs every other session out.
+	ChangePassword(claims *utils.Claims, req *dto.ChangePasswordRequest) (*dto.RevokeSessionsResponse, error)
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	sessionRepo    repository.SessionRepository
+	identityRepo   repository.IdentityRepository
+	verification   EmailVerificationService
+	mfa            MFAService
+	passkeys       PasskeyService
+	magicLinks     MagicLinkService
+	loginLimiter   LoginLimiter
+	googleVerifier IDTokenVerifier
+	providers      map[string]IdentityProvider
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
+// NewAuthService builds the auth service. googleVerifier may be nil, which
+// turns Google ID-token sign-in off; providers are the OAuth sign-in
+// providers that are configured.
+func NewAuthService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, identityRepo repository.IdentityRepository, verification EmailVerificationService, mfa MFAService, passkeys PasskeyService, magicLinks MagicLinkService, loginLimiter LoginLimiter, googleVerifier IDTokenVerifier, providers ...IdentityProvider) AuthService {
+	byName := make(map[string]IdentityProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
 	return &authService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		identityRepo:   identityRepo,
+		verification:   verification,
+		mfa:            mfa,
+		passkeys:       passkeys,
+		magicLinks:     magicLinks,
+		loginLimiter:   loginLimiter,
+		googleVerifier: googleVerifier,
+		providers:      byName,
 	}
 }
 
-func (s *authService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, string, string, error) {
+func (s *authService) Register(req *dto.RegisterRequest, client ClientInfo) (*dto.AuthResponse, string, string, error) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
 	existingUser, err := s.userRepo.FindByEmail(email)
@@ -44,19 +161,20 @@ func (s *authService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, str
 	user := &models.User{
 		Name:     strings.TrimSpace(req.Name),
 		Email:    email,
-		Password: req.Password,
+		Password: &req.Password,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, "", "", err
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email)
-	if err != nil {
-		return nil, "", "", err
+	// The account works right away; features that need a verified email
+	// wait for the link in this message.
+	if err := s.verification.SendVerification(user, client.Locale); err != nil {
+		log.Printf("Failed to start email verification: %v", err)
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID, user.Email)
+	accessToken, refreshToken, err := s.startSession(user, client)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -72,27 +190,133 @@ func (s *authService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, str
 	return response, accessToken, refreshToken, nil
 }
 
-func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, string, string, error) {
+func (s *authService) Login(req *dto.LoginRequest, client ClientInfo) (*dto.AuthResponse, string, string, error) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	wait, err := s.loginLimiter.Begin(email, client.IPAddress)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if wait > 0 {
+		return nil, "", "", &ThrottledError{RetryAfter: wait}
+	}
+
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", "", errors.New("invalid email or password")
+			// Unknown emails cost the same time and count the same as
+			// wrong passwords, so neither reveals which accounts exist.
+			models.CompareDummyPassword(req.Password)
+			return nil, "", "", s.failLogin(email, client)
 		}
 		return nil, "", "", err
 	}
 
 	if !user.ComparePassword(req.Password) {
-		return nil, "", "", errors.New("invalid email or password")
+		return nil, "", "", s.failLogin(email, client)
+	}
+	if err := s.loginLimiter.Succeed(email, client.IPAddress); err != nil {
+		return nil, "", "", err
+	}
+
+	return s.completeSignIn(user, client, "Login successful")
+}
+
+// failLogin records a wrong password and returns the error to answer
+// with.
+func (s *authService) failLogin(email string, client ClientInfo) error {
+	if err := s.loginLimiter.Fail(email, client.IPAddress); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// completeSignIn starts a session for a user who proved their identity, or,
+// when two-factor authentication is on, returns an MFA token to exchange
+// at VerifyMFA instead of session tokens.
+func (s *authService) completeSignIn(user *models.User, client ClientInfo, message string) (*dto.AuthResponse, string, string, error) {
+	if user.MFAEnabled() {
+		mfaToken, err := utils.GenerateMFAToken(user.ID, MFATokenTTL)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return &dto.AuthResponse{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, "", "", nil
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, client)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	response := &dto.AuthResponse{
+		Success: true,
+		Message: message,
+		Data: &dto.AuthData{
+			User: mapUserToDTO(user),
+		},
+	}
+
+	return response, accessToken, refreshToken, nil
+}
+
+// VerifyMFA finishes a sign-in that completeSignIn paused for a second
+// factor. Each MFA token works once.
+func (s *authService) VerifyMFA(req *dto.MFAVerifyRequest, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	claims, err := utils.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, "", "", ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", ErrInvalidMFAToken
+		}
+		return nil, "", "", err
+	}
+	if !user.MFAEnabled() {
+		return nil, "", "", ErrInvalidMFAToken
+	}
+
+	if err := s.mfa.Authenticate(user, req.Code); err != nil {
+		return nil, "", "", err
+	}
+	if err := utils.RevokeMFAToken(claims); err != nil {
+		return nil, "", "", err
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, client)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	response := &dto.AuthResponse{
+		Success: true,
+		Message: "Login successful",
+		Data: &dto.AuthData{
+			User: mapUserToDTO(user),
+		},
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email)
+	return response, accessToken, refreshToken, nil
+}
+
+func (s *authService) PasskeyLoginOptions() (*dto.PasskeyOptionsResponse, error) {
+	return s.passkeys.LoginOptions()
+}
+
+func (s *authService) PasskeyLogin(req *dto.PasskeyLoginRequest, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	user, err := s.passkeys.Authenticate(req)
 	if err != nil {
 		return nil, "", "", err
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID, user.Email)
+	accessToken, refreshToken, err := s.startSession(user, client)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -108,41 +332,531 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, string, s
 	return response, accessToken, refreshToken, nil
 }
 
-func (s *authService) RefreshToken(refreshToken string) (string, string, error) {
+func (s *authService) RequestMagicLink(req *dto.MagicLinkRequest, locale string) (*dto.TokenResponse, string, error) {
+	return s.magicLinks.Request(req, locale)
+}
+
+func (s *authService) MagicLinkLogin(req *dto.MagicLinkConsumeRequest, nonce string, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	user, created, err := s.magicLinks.Authenticate(req, nonce, client.Locale)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	message := "Login successful"
+	if created {
+		message = "Account created successfully"
+	}
+
+	return s.completeSignIn(user, client, message)
+}
+
+// GoogleLogin signs in with a Google ID token from the mobile app's native
+// Google Sign-In.
+func (s *authService) GoogleLogin(ctx context.Context, req *dto.GoogleAuthRequest, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	if s.googleVerifier == nil {
+		return nil, "", "", ErrGoogleSignInDisabled
+	}
+
+	claims, err := s.googleVerifier.Verify(ctx, req.IDToken)
+	if err != nil || claims.Email == "" {
+		return nil, "", "", ErrInvalidGoogleToken
+	}
+
+	return s.signInWithIdentity(&oauth.Identity{
+		Provider:      constants.OAuthProviderGoogle,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, client)
+}
+
+// OAuthStart begins a sign-in with provider. It returns the URL to send
+// the browser to and a signed state token the caller must hand back to
+// OAuthCallback, normally through a cookie.
+func (s *authService) OAuthStart(provider string) (string, string, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	req, err := oauth.NewAuthRequest()
+	if err != nil {
+		return "", "", err
+	}
+
+	stateToken, err := utils.GenerateOAuthStateToken(&utils.OAuthStateClaims{
+		Provider:     provider,
+		State:        req.State,
+		CodeVerifier: req.CodeVerifier,
+		Nonce:        req.Nonce,
+	}, OAuthStateTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	return idp.AuthCodeURL(req), stateToken, nil
+}
+
+// OAuthCallback finishes a sign-in started by OAuthStart: it checks state
+// against the state token, redeems code with the PKCE verifier and signs
+// the provider's user in.
+func (s *authService) OAuthCallback(ctx context.Context, provider, code, state, stateToken string, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	idp, ok := s.providers[provider]
+	if !ok {
+		return nil, "", "", ErrUnknownProvider
+	}
+
+	claims, err := utils.ValidateOAuthStateToken(stateToken)
+	if err != nil || claims.Provider != provider || state == "" ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, "", "", ErrInvalidOAuthState
+	}
+	if code == "" {
+		return nil, "", "", ErrOAuthExchangeFailed
+	}
+
+	identity, err := idp.Exchange(ctx, code, &oauth.AuthRequest{
+		State:        claims.State,
+		CodeVerifier: claims.CodeVerifier,
+		Nonce:        claims.Nonce,
+	})
+	if err != nil {
+		return nil, "", "", ErrOAuthExchangeFailed
+	}
+
+	return s.signInWithIdentity(identity, client)
+}
+
+// signInWithIdentity signs in the user linked to an external identity. An
+// unknown identity is linked to the account with the same verified email,
+// or gets a new password-less account.
+func (s *authService) signInWithIdentity(identity *oauth.Identity, client ClientInfo) (*dto.AuthResponse, string, string, error) {
+	user, created, err := s.findOrCreateIdentityUser(identity, client.Locale)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	message := "Login successful"
+	if created {
+		message = "Account created successfully"
+	}
+
+	return s.completeSignIn(user, client, message)
+}
+
+func (s *authService) findOrCreateIdentityUser(identity *oauth.Identity, locale string) (*models.User, bool, error) {
+	picture := trimmedOrNil(&identity.Picture)
+	if picture != nil && len(*picture) > 500 {
+		picture = nil
+	}
+
+	linked, err := s.identityRepo.FindByProviderSubject(identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.FindByID(linked.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.identityRepo.TouchLastLogin(linked.ID); err != nil {
+			return nil, false, err
+		}
+		if identity.EmailVerified && strings.EqualFold(strings.TrimSpace(identity.Email), user.Email) {
+			if err := s.verification.ConfirmOwnership(user, locale); err != nil {
+				return nil, false, err
+			}
+		}
+		if user.Avatar == nil && picture != nil {
+			user.Avatar = picture
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, false, err
+			}
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	// Only an address the provider has verified proves the caller owns the
+	// matching local account.
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	if email == "" || !identity.EmailVerified {
+		return nil, false, ErrEmailNotVerified
+	}
+
+	provider := identity.Provider
+	subject := identity.Subject
+	newIdentity := &models.UserIdentity{
+		Provider: provider,
+		Subject:  subject,
+		Email:    &email,
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err == nil {
+		_, err := s.identityRepo.FindByUserAndProvider(user.ID, provider)
+		if err == nil {
+			return nil, false, ErrOAuthAccountConflict
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, err
+		}
+
+		newIdentity.UserID = user.ID
+		if err := s.identityRepo.Create(newIdentity); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return nil, false, ErrOAuthAccountConflict
+			}
+			return nil, false, err
+		}
+
+		if err := s.verification.ConfirmOwnership(user, locale); err != nil {
+			return nil, false, err
+		}
+		changed := false
+		if user.OAuthProvider == nil {
+			user.OAuthProvider = &provider
+			user.OAuthID = &subject
+			changed = true
+		}
+		if user.Avatar == nil && picture != nil {
+			user.Avatar = picture
+			changed = true
+		}
+		if changed {
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, false, err
+			}
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	now := time.Now()
+	user = &models.User{
+		Name:            truncate(name, 100),
+		Email:           email,
+		Avatar:          picture,
+		OAuthProvider:   &provider,
+		OAuthID:         &subject,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.identityRepo.CreateWithUser(user, newIdentity); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. Each refresh
+// token works once; presenting one that was already rotated means it was
+// copied, so every session in its family is revoked.
+func (s *authService) RefreshToken(refreshToken string, client ClientInfo) (string, string, error) {
 	claims, err := utils.ValidateToken(refreshToken, true)
 	if err != nil {
-		return "", "", errors.New("invalid or expired refresh token")
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.FindByTokenHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
+		return "", "", err
+	}
+
+	if session.RevokedAt != nil || session.UserID != claims.UserID || !time.Now().Before(session.ExpiresAt) {
+		return "", "", ErrInvalidRefreshToken
+	}
+	if session.RotatedAt != nil {
+		if err := s.sessionRepo.RevokeFamily(session.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRefreshTokenReused
 	}
 
 	user, err := s.ValidateUser(claims.UserID)
 	if err != nil {
-		return "", "", errors.New("invalid refresh token")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
+		return "", "", err
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email)
+	next, accessToken, newRefreshToken, err := s.newSession(user, session.FamilyID, client)
 	if err != nil {
 		return "", "", err
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(user.ID, user.Email)
+	rotated, err := s.sessionRepo.Rotate(session, next)
 	if err != nil {
 		return "", "", err
 	}
+	if !rotated {
+		// Another request rotated this token between our read and write.
+		if err := s.sessionRepo.RevokeFamily(session.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRefreshTokenReused
+	}
 
 	return accessToken, newRefreshToken, nil
 }
 
+// Logout revokes the access token in claims and, when given, the refresh
+// token, then ends the session family they belong to so no refresh token
+// from this sign-in works again.
+func (s *authService) Logout(claims *utils.Claims, refreshToken string) error {
+	if err := utils.RevokeToken(claims); err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		refreshClaims, err := utils.ValidateToken(refreshToken, true)
+		if err == nil && refreshClaims.UserID == claims.UserID {
+			if err := utils.RevokeToken(refreshClaims); err != nil {
+				return err
+			}
+		}
+	}
+
+	session, err := s.sessionRepo.FindByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.UserID != claims.UserID {
+		return nil
+	}
+	return s.sessionRepo.RevokeFamily(session.FamilyID)
+}
+
+func (s *authService) ListSessions(claims *utils.Claims) (*dto.SessionListResponse, error) {
+	currentFamilyID, err := s.currentFamily(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUser(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, dto.SessionResponse{
+			ID:         session.FamilyID.String(),
+			Device:     useragent.DeviceName(session.UserAgent),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.SignedInAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+			LastSeenAt: session.LastUsedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+			Current:    session.FamilyID == currentFamilyID,
+		})
+	}
+
+	response := &dto.SessionListResponse{
+		Success: true,
+		Message: "Sessions retrieved successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+// RevokeSession signs out one of the user's devices. Its refresh token stops
+// working at once and an access token it already holds within
+// revocation.SessionCacheTTL.
+func (s *authService) RevokeSession(claims *utils.Claims, sessionID uuid.UUID) error {
+	revoked, err := s.sessionRepo.RevokeUserFamily(claims.UserID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *authService) RevokeOtherSessions(claims *utils.Claims) (*dto.RevokeSessionsResponse, error) {
+	currentFamilyID, err := s.currentFamily(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.sessionRepo.RevokeOtherFamilies(claims.UserID, currentFamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.RevokeSessionsResponse{
+		Success: true,
+		Message: fmt.Sprintf("Signed out of %d other session(s)", revoked),
+		Revoked: revoked,
+	}
+
+	return response, nil
+}
+
+// currentFamily returns the sign-in the access token in claims belongs to.
+func (s *authService) currentFamily(claims *utils.Claims) (uuid.UUID, error) {
+	session, err := s.sessionRepo.FindByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, err
+	}
+	if session.UserID != claims.UserID {
+		return uuid.Nil, nil
+	}
+	return session.FamilyID, nil
+}
+
 func (s *authService) ValidateUser(userID uuid.UUID) (*models.User, error) {
 	return s.userRepo.FindByID(userID)
 }
 
+func (s *authService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (*dto.AuthResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if len([]rune(name)) < 2 {
+			return nil, &dto.ValidationError{Field: "name", Message: "Name must be at least 2 characters"}
+		}
+		user.Name = name
+	}
+
+	if req.Avatar != nil {
+		avatar := trimmedOrNil(req.Avatar)
+		if avatar != nil {
+			parsed, err := url.Parse(*avatar)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, &dto.ValidationError{Field: "avatar", Message: "Avatar must be an http or https URL"}
+			}
+		}
+		user.Avatar = avatar
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		Success: true,
+		Message: "Profile updated successfully",
+		Data: &dto.AuthData{
+			User: mapUserToDTO(user),
+		},
+	}, nil
+}
+
+func (s *authService) ChangePassword(claims *utils.Claims, req *dto.ChangePasswordRequest) (*dto.RevokeSessionsResponse, error) {
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasPassword() {
+		return nil, ErrNoPasswordSet
+	}
+	if !user.ComparePassword(req.CurrentPassword) {
+		return nil, ErrIncorrectPassword
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, &dto.ValidationError{Field: "newPassword", Message: "New password must be different from the current password"}
+	}
+
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	currentFamilyID, err := s.currentFamily(claims)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.sessionRepo.RevokeOtherFamilies(claims.UserID, currentFamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevokeSessionsResponse{
+		Success: true,
+		Message: "Password updated successfully",
+		Revoked: revoked,
+	}, nil
+}
+
+// startSession signs the user in on a new device session and returns its
+// access and refresh tokens.
+func (s *authService) startSession(user *models.User, client ClientInfo) (string, string, error) {
+	session, accessToken, refreshToken, err := s.newSession(user, uuid.Nil, client)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.sessionRepo.Create(session); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// newSession builds an unsaved session in familyID, or in a new family when
+// familyID is uuid.Nil, together with the tokens issued for it.
+func (s *authService) newSession(user *models.User, familyID uuid.UUID, client ClientInfo) (*models.Session, string, string, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		UserAgent:  truncate(client.UserAgent, maxUserAgentLength),
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  now.Add(config.AppConfig.JWT.RefreshExpires),
+		LastUsedAt: now,
+	}
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
+
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	session.TokenHash = utils.HashToken(refreshToken)
+
+	return session, accessToken, refreshToken, nil
+}
+
 func mapUserToDTO(user *models.User) dto.UserResponse {
 	return dto.UserResponse{
-		ID:        user.ID.String(),
-		Name:      user.Name,
-		Email:     user.Email,
-		Avatar:    user.Avatar,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
+		ID:            user.ID.String(),
+		Name:          user.Name,
+		Email:         user.Email,
+		Avatar:        user.Avatar,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		CreatedAt:     user.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		UpdatedAt:     user.UpdatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
 	}
 }
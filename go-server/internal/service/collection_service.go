@@ -0,0 +1,430 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/pagination"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCollectionNotFound     = errors.New("collection not found")
+	ErrLinkNotFound           = errors.New("link not found")
+	ErrCollectionItemExists   = errors.New("link is already in this collection")
+	ErrCollectionItemNotFound = errors.New("link is not in this collection")
+)
+
+type CollectionService interface {
+	Create(userID uuid.UUID, req *dto.CreateCollectionRequest) (*dto.CollectionDetailResponse, error)
+	List(userID uuid.UUID, query *dto.PaginationQuery) (*dto.CollectionListResponse, error)
+	Get(userID, collectionID uuid.UUID) (*dto.CollectionDetailResponse, error)
+	Update(userID, collectionID uuid.UUID, req *dto.UpdateCollectionRequest) (*dto.CollectionDetailResponse, error)
+	Delete(userID, collectionID uuid.UUID) error
+	ListItems(userID, collectionID uuid.UUID, query *dto.PaginationQuery) (*dto.CollectionItemListResponse, error)
+	AddItem(userID, collectionID uuid.UUID, req *dto.AddCollectionItemRequest) (*dto.CollectionItemDetailResponse, error)
+	RemoveItem(userID, collectionID, linkID uuid.UUID) error
+	MoveItem(userID, collectionID, linkID uuid.UUID, req *dto.MoveCollectionItemRequest) (*dto.CollectionItemDetailResponse, error)
+}
+
+type collectionService struct {
+	collectionRepo repository.CollectionRepository
+	linkRepo       repository.LinkRepository
+	tagRepo        repository.TagRepository
+}
+
+func NewCollectionService(collectionRepo repository.CollectionRepository, linkRepo repository.LinkRepository, tagRepo repository.TagRepository) CollectionService {
+	return &collectionService{
+		collectionRepo: collectionRepo,
+		linkRepo:       linkRepo,
+		tagRepo:        tagRepo,
+	}
+}
+
+func (s *collectionService) Create(userID uuid.UUID, req *dto.CreateCollectionRequest) (*dto.CollectionDetailResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &dto.ValidationError{Field: "name", Message: "This field is required"}
+	}
+
+	collection := &models.Collection{
+		UserID:      userID,
+		Name:        name,
+		Description: trimmedOrNil(req.Description),
+	}
+
+	if err := s.collectionRepo.Create(collection); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionDetailResponse{
+		Success: true,
+		Message: "Collection created successfully",
+		Data:    mapCollectionToDTO(&repository.CollectionWithCount{Collection: *collection}),
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) List(userID uuid.UUID, query *dto.PaginationQuery) (*dto.CollectionListResponse, error) {
+	after, err := pagination.Decode(query.Cursor)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: "cursor", Message: "Invalid cursor"}
+	}
+	limit := pagination.Limit(query.Limit)
+
+	collections, err := s.collectionRepo.FindAllByUser(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.Pagination{Limit: limit}
+	if len(collections) > limit {
+		collections = collections[:limit]
+		last := collections[len(collections)-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		page.NextCursor = &next
+		page.HasMore = true
+	}
+
+	data := make([]dto.CollectionResponse, 0, len(collections))
+	for i := range collections {
+		data = append(data, *mapCollectionToDTO(&collections[i]))
+	}
+
+	response := &dto.CollectionListResponse{
+		Success:    true,
+		Message:    "Collections retrieved successfully",
+		Data:       data,
+		Pagination: page,
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) Get(userID, collectionID uuid.UUID) (*dto.CollectionDetailResponse, error) {
+	collection, err := s.findCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionDetailResponse{
+		Success: true,
+		Message: "Collection retrieved successfully",
+		Data:    mapCollectionToDTO(collection),
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) Update(userID, collectionID uuid.UUID, req *dto.UpdateCollectionRequest) (*dto.CollectionDetailResponse, error) {
+	collection, err := s.findCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, &dto.ValidationError{Field: "name", Message: "This field is required"}
+		}
+		collection.Name = name
+	}
+	if req.Description != nil {
+		collection.Description = trimmedOrNil(req.Description)
+	}
+
+	if err := s.collectionRepo.Update(&collection.Collection); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionDetailResponse{
+		Success: true,
+		Message: "Collection updated successfully",
+		Data:    mapCollectionToDTO(collection),
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) Delete(userID, collectionID uuid.UUID) error {
+	deleted, err := s.collectionRepo.Delete(collectionID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+func (s *collectionService) ListItems(userID, collectionID uuid.UUID, query *dto.PaginationQuery) (*dto.CollectionItemListResponse, error) {
+	if _, err := s.findCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	data, page, err := listCollectionItems(s.collectionRepo, s.tagRepo, collectionID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionItemListResponse{
+		Success:    true,
+		Message:    "Collection items retrieved successfully",
+		Data:       data,
+		Pagination: page,
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) AddItem(userID, collectionID uuid.UUID, req *dto.AddCollectionItemRequest) (*dto.CollectionItemDetailResponse, error) {
+	if _, err := s.findCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	linkID, err := uuid.Parse(req.LinkID)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: "link_id", Message: "Invalid value"}
+	}
+
+	link, err := s.linkRepo.FindByID(linkID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+
+	last, ok, err := s.collectionRepo.LastPosition(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	position := repository.PositionGap
+	if ok {
+		position = last + repository.PositionGap
+	}
+
+	item := &models.CollectionItem{
+		CollectionID: collectionID,
+		LinkID:       link.ID,
+		Position:     position,
+	}
+
+	if err := s.collectionRepo.AddItem(item); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCollectionItemExists
+		}
+		return nil, err
+	}
+
+	data := mapCollectionItemToDTO(&repository.CollectionItemRow{
+		Link:     *link,
+		Position: item.Position,
+		AddedAt:  item.AddedAt,
+	})
+	if err := loadTags(s.tagRepo, &data.LinkResponse); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionItemDetailResponse{
+		Success: true,
+		Message: "Link added to collection",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+func (s *collectionService) RemoveItem(userID, collectionID, linkID uuid.UUID) error {
+	if _, err := s.findCollection(userID, collectionID); err != nil {
+		return err
+	}
+
+	removed, err := s.collectionRepo.RemoveItem(collectionID, linkID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrCollectionItemNotFound
+	}
+	return nil
+}
+
+func (s *collectionService) MoveItem(userID, collectionID, linkID uuid.UUID, req *dto.MoveCollectionItemRequest) (*dto.CollectionItemDetailResponse, error) {
+	if _, err := s.findCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.findItem(collectionID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var afterID *uuid.UUID
+	if req.AfterLinkID != nil {
+		id, err := uuid.Parse(*req.AfterLinkID)
+		if err != nil {
+			return nil, &dto.ValidationError{Field: "after_link_id", Message: "Invalid value"}
+		}
+		afterID = &id
+	}
+
+	if afterID == nil || *afterID != linkID {
+		// Only rebalance when the target gap is exhausted, then retry once.
+		position, ok, err := s.positionAfter(collectionID, linkID, afterID)
+		if err == nil && !ok {
+			if err = s.collectionRepo.Rebalance(collectionID); err == nil {
+				position, ok, err = s.positionAfter(collectionID, linkID, afterID)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("failed to find a free position in collection")
+		}
+
+		if err := s.collectionRepo.UpdateItemPosition(collectionID, linkID, position); err != nil {
+			return nil, err
+		}
+		item.Position = position
+	}
+
+	link, err := s.linkRepo.FindByID(linkID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := mapCollectionItemToDTO(&repository.CollectionItemRow{
+		Link:     *link,
+		Position: item.Position,
+		AddedAt:  item.AddedAt,
+	})
+	if err := loadTags(s.tagRepo, &data.LinkResponse); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionItemDetailResponse{
+		Success: true,
+		Message: "Collection item moved",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+// positionAfter picks a position for linkID directly after afterID, or at
+// the start when afterID is nil. ok is false when the neighbours are
+// adjacent and the collection has to be rebalanced first.
+func (s *collectionService) positionAfter(collectionID, linkID uuid.UUID, afterID *uuid.UUID) (int64, bool, error) {
+	if afterID == nil {
+		first, exists, err := s.collectionRepo.FirstPosition(collectionID, linkID)
+		if err != nil {
+			return 0, false, err
+		}
+		if !exists {
+			return repository.PositionGap, true, nil
+		}
+		return first - repository.PositionGap, true, nil
+	}
+
+	after, err := s.findItem(collectionID, *afterID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	next, exists, err := s.collectionRepo.NextPosition(collectionID, after.Position, linkID)
+	if err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return after.Position + repository.PositionGap, true, nil
+	}
+	if next-after.Position < 2 {
+		return 0, false, nil
+	}
+	return after.Position + (next-after.Position)/2, true, nil
+}
+
+func (s *collectionService) findCollection(userID, collectionID uuid.UUID) (*repository.CollectionWithCount, error) {
+	collection, err := s.collectionRepo.FindByID(collectionID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (s *collectionService) findItem(collectionID, linkID uuid.UUID) (*models.CollectionItem, error) {
+	item, err := s.collectionRepo.FindItem(collectionID, linkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionItemNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// listCollectionItems loads one page of a collection's items, with tags.
+func listCollectionItems(collectionRepo repository.CollectionRepository, tagRepo repository.TagRepository, collectionID uuid.UUID, query *dto.PaginationQuery) ([]dto.CollectionItemResponse, *dto.Pagination, error) {
+	after, err := pagination.Decode(query.Cursor)
+	if err != nil {
+		return nil, nil, &dto.ValidationError{Field: "cursor", Message: "Invalid cursor"}
+	}
+	limit := pagination.Limit(query.Limit)
+
+	rows, err := collectionRepo.FindItems(collectionID, after, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &dto.Pagination{Limit: limit}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		next := pagination.Cursor{Rank: float64(last.Position), ID: last.ID}.Encode()
+		page.NextCursor = &next
+		page.HasMore = true
+	}
+
+	data := make([]dto.CollectionItemResponse, 0, len(rows))
+	for i := range rows {
+		data = append(data, *mapCollectionItemToDTO(&rows[i]))
+	}
+	responses := make([]*dto.LinkResponse, 0, len(data))
+	for i := range data {
+		responses = append(responses, &data[i].LinkResponse)
+	}
+	if err := loadTags(tagRepo, responses...); err != nil {
+		return nil, nil, err
+	}
+
+	return data, page, nil
+}
+
+func mapCollectionToDTO(collection *repository.CollectionWithCount) *dto.CollectionResponse {
+	return &dto.CollectionResponse{
+		ID:          collection.ID.String(),
+		Name:        collection.Name,
+		Description: collection.Description,
+		ItemCount:   collection.ItemCount,
+		CreatedAt:   collection.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		UpdatedAt:   collection.UpdatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+}
+
+func mapCollectionItemToDTO(row *repository.CollectionItemRow) *dto.CollectionItemResponse {
+	return &dto.CollectionItemResponse{
+		LinkResponse: *mapLinkToDTO(&row.Link),
+		Position:     row.Position,
+		AddedAt:      row.AddedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+}
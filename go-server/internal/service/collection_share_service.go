@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const shareTokenBytes = 32
+
+var (
+	ErrShareNotFound         = errors.New("share link not found")
+	ErrShareExpired          = errors.New("share link has expired")
+	ErrSharePasswordRequired = errors.New("password required")
+	ErrSharePasswordInvalid  = errors.New("invalid password")
+)
+
+type CollectionShareService interface {
+	Create(userID, collectionID uuid.UUID, req *dto.CreateCollectionShareRequest) (*dto.CollectionShareDetailResponse, error)
+	List(userID, collectionID uuid.UUID) (*dto.CollectionShareListResponse, error)
+	Revoke(userID, collectionID, shareID uuid.UUID) error
+
+	// Resolve finds the active share for a token, checking its password
+	// when it has one. Wrong passwords count against the share, and it
+	// returns a *ThrottledError while they have been tried too often.
+	Resolve(token, password string) (*models.CollectionShare, error)
+	GetShared(share *models.CollectionShare) (*dto.CollectionDetailResponse, error)
+	ListSharedItems(share *models.CollectionShare, query *dto.PaginationQuery) (*dto.CollectionItemListResponse, error)
+}
+
+type collectionShareService struct {
+	shareRepo      repository.CollectionShareRepository
+	collectionRepo repository.CollectionRepository
+	tagRepo        repository.TagRepository
+	limiter        LoginLimiter
+}
+
+func NewCollectionShareService(shareRepo repository.CollectionShareRepository, collectionRepo repository.CollectionRepository, tagRepo repository.TagRepository, limiter LoginLimiter) CollectionShareService {
+	return &collectionShareService{
+		shareRepo:      shareRepo,
+		collectionRepo: collectionRepo,
+		tagRepo:        tagRepo,
+		limiter:        limiter,
+	}
+}
+
+func (s *collectionShareService) Create(userID, collectionID uuid.UUID, req *dto.CreateCollectionShareRequest) (*dto.CollectionShareDetailResponse, error) {
+	if err := s.checkOwner(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, &dto.ValidationError{Field: "expires_at", Message: "Expiry must be in the future"}
+	}
+
+	token, err := utils.GenerateSecureToken(shareTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.CollectionShare{
+		CollectionID: collectionID,
+		TokenHash:    utils.HashToken(token),
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if req.Password != nil {
+		if err := share.SetPassword(*req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.shareRepo.Create(share); err != nil {
+		return nil, err
+	}
+
+	data := mapCollectionShareToDTO(share)
+	data.Token = token
+	data.URL = strings.TrimRight(config.AppConfig.Share.BaseURL, "/") + "/" + token
+
+	response := &dto.CollectionShareDetailResponse{
+		Success: true,
+		Message: "Share link created successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+func (s *collectionShareService) List(userID, collectionID uuid.UUID) (*dto.CollectionShareListResponse, error) {
+	if err := s.checkOwner(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shareRepo.FindByCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.CollectionShareResponse, 0, len(shares))
+	for i := range shares {
+		data = append(data, *mapCollectionShareToDTO(&shares[i]))
+	}
+
+	response := &dto.CollectionShareListResponse{
+		Success: true,
+		Message: "Share links retrieved successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+func (s *collectionShareService) Revoke(userID, collectionID, shareID uuid.UUID) error {
+	if err := s.checkOwner(userID, collectionID); err != nil {
+		return err
+	}
+
+	revoked, err := s.shareRepo.Revoke(shareID, collectionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+func (s *collectionShareService) Resolve(token, password string) (*models.CollectionShare, error) {
+	if token == "" {
+		return nil, ErrShareNotFound
+	}
+
+	share, err := s.shareRepo.FindByTokenHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	// A revoked share looks exactly like one that never existed.
+	if share.RevokedAt != nil || share.Collection == nil {
+		return nil, ErrShareNotFound
+	}
+	if share.Expired(time.Now()) {
+		return nil, ErrShareExpired
+	}
+
+	if share.HasPassword() {
+		if password == "" {
+			return nil, ErrSharePasswordRequired
+		}
+		if err := s.checkPassword(share, password); err != nil {
+			return nil, err
+		}
+	}
+
+	return share, nil
+}
+
+// checkPassword counts guesses per share rather than per client, since
+// anyone holding the link can try from any number of addresses.
+func (s *collectionShareService) checkPassword(share *models.CollectionShare, password string) error {
+	subject := "share:" + share.ID.String()
+	wait, err := s.limiter.Begin(subject, "")
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+
+	if share.ComparePassword(password) {
+		return s.limiter.Succeed(subject, "")
+	}
+	if err := s.limiter.Fail(subject, ""); err != nil {
+		return err
+	}
+	return ErrSharePasswordInvalid
+}
+
+// GetShared returns the shared collection and counts the visit.
+func (s *collectionShareService) GetShared(share *models.CollectionShare) (*dto.CollectionDetailResponse, error) {
+	collection, err := s.collectionRepo.FindByID(share.CollectionID, share.Collection.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.shareRepo.RecordView(share.ID); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CollectionDetailResponse{
+		Success: true,
+		Message: "Collection retrieved successfully",
+		Data:    mapCollectionToDTO(collection),
+	}
+
+	return response, nil
+}
+
+// ListSharedItems lists the shared collection's links. The owner's private
+// notes are left out.
+func (s *collectionShareService) ListSharedItems(share *models.CollectionShare, query *dto.PaginationQuery) (*dto.CollectionItemListResponse, error) {
+	data, page, err := listCollectionItems(s.collectionRepo, s.tagRepo, share.CollectionID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notes and tags are the owner's own; the share only shows the links.
+	for i := range data {
+		data[i].Notes = nil
+		data[i].Tags = []string{}
+	}
+
+	response := &dto.CollectionItemListResponse{
+		Success:    true,
+		Message:    "Collection items retrieved successfully",
+		Data:       data,
+		Pagination: page,
+	}
+
+	return response, nil
+}
+
+func (s *collectionShareService) checkOwner(userID, collectionID uuid.UUID) error {
+	if _, err := s.collectionRepo.FindByID(collectionID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCollectionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func mapCollectionShareToDTO(share *models.CollectionShare) *dto.CollectionShareResponse {
+	return &dto.CollectionShareResponse{
+		ID:           share.ID.String(),
+		HasPassword:  share.HasPassword(),
+		ExpiresAt:    formatTimeOrNil(share.ExpiresAt),
+		RevokedAt:    formatTimeOrNil(share.RevokedAt),
+		ViewCount:    share.ViewCount,
+		LastViewedAt: formatTimeOrNil(share.LastViewedAt),
+		CreatedAt:    share.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+}
+
+func formatTimeOrNil(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format("2006-01-02T15:04:05.000Z")
+	return &formatted
+}
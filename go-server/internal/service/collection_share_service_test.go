@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/lockout"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/pagination"
+	"github.com/video-mobile-app/go-server/internal/repository"
+)
+
+// oneShareRepo finds a single share, whatever the token.
+type oneShareRepo struct {
+	repository.CollectionShareRepository
+	share *models.CollectionShare
+}
+
+func (r *oneShareRepo) FindByTokenHash(string) (*models.CollectionShare, error) {
+	return r.share, nil
+}
+
+func TestResolveBacksOffWrongSharePasswords(t *testing.T) {
+	share := &models.CollectionShare{ID: uuid.New(), Collection: &models.Collection{}}
+	if err := share.SetPassword("picnic-basket"); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	limiter := lockout.NewTracker(lockout.NewMemoryStore(), lockout.Options{
+		Window:  time.Hour,
+		Account: lockout.Policy{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	})
+	shares := NewCollectionShareService(&oneShareRepo{share: share}, nil, nil, limiter)
+
+	for i := 0; i < 4; i++ {
+		if _, err := shares.Resolve("token", "wrong-guess"); !errors.Is(err, ErrSharePasswordInvalid) {
+			t.Fatalf("wrong password %d: %v, want ErrSharePasswordInvalid", i+1, err)
+		}
+	}
+
+	var throttled *ThrottledError
+	if _, err := shares.Resolve("token", "picnic-basket"); !errors.As(err, &throttled) {
+		t.Fatalf("password after 4 failures: %v, want a *ThrottledError", err)
+	}
+}
+
+func TestFormatTimeOrNilUsesUTC(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	if got := formatTimeOrNil(&at); got == nil || *got != "2026-03-01T07:30:00.000Z" {
+		t.Fatalf("formatTimeOrNil = %v, want 2026-03-01T07:30:00.000Z", got)
+	}
+}
+
+// oneItemCollectionRepo holds a collection with a single link.
+type oneItemCollectionRepo struct {
+	repository.CollectionRepository
+	item repository.CollectionItemRow
+}
+
+func (r *oneItemCollectionRepo) FindItems(uuid.UUID, *pagination.Cursor, int) ([]repository.CollectionItemRow, error) {
+	return []repository.CollectionItemRow{r.item}, nil
+}
+
+// privateTagRepo tags every link "private".
+type privateTagRepo struct {
+	repository.TagRepository
+}
+
+func (privateTagRepo) FindNamesByLinks(linkIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	names := make(map[uuid.UUID][]string, len(linkIDs))
+	for _, id := range linkIDs {
+		names[id] = []string{"private"}
+	}
+	return names, nil
+}
+
+func TestListSharedItemsHidesTheOwnersNotesAndTags(t *testing.T) {
+	notes := "for me only"
+	collections := &oneItemCollectionRepo{item: repository.CollectionItemRow{
+		Link: models.Link{ID: uuid.New(), URL: "https://youtu.be/dQw4w9WgXcQ", Notes: &notes},
+	}}
+	shares := NewCollectionShareService(nil, collections, privateTagRepo{}, nil)
+
+	response, err := shares.ListSharedItems(&models.CollectionShare{CollectionID: uuid.New()}, &dto.PaginationQuery{})
+	if err != nil {
+		t.Fatalf("ListSharedItems: %v", err)
+	}
+	item := response.Data[0]
+	if item.Notes != nil || len(item.Tags) != 0 {
+		t.Fatalf("shared item notes %v, tags %v; want neither", item.Notes, item.Tags)
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/mailer"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTokenBytes = 32
+	// EmailVerificationTTL is how long a verification link stays valid.
+	EmailVerificationTTL = 24 * time.Hour
+
+	// A user may ask for a new verification email once a minute and five
+	// times an hour.
+	verificationResendInterval = time.Minute
+	verificationResendWindow   = time.Hour
+	verificationResendLimit    = 5
+
+	templateEmailVerification = "email_verification"
+	templatePasswordRemoved   = "password_removed"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("verification link is invalid or has expired")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+)
+
+// ThrottledError is returned when a caller must wait before retrying.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, try again in %d seconds", int(e.RetryAfter.Round(time.Second).Seconds()))
+}
+
+type EmailVerificationService interface {
+	// SendVerification emails user a link proving they own their address.
+	// locale picks the email language, as a tag or Accept-Language value.
+	SendVerification(user *models.User, locale string) error
+	Verify(req *dto.VerifyEmailRequest) (*dto.AuthResponse, error)
+	Resend(userID uuid.UUID, locale string) (*dto.TokenResponse, error)
+	IsVerified(userID uuid.UUID) (bool, error)
+	// ConfirmOwnership marks user's email verified after they proved they
+	// own it another way: a sign-in link, or a provider that verified it.
+	// Whoever set the password of an account that was unverified until
+	// now never proved they own the address, so the password and the
+	// sessions it opened are dropped, and the owner is told by email.
+	ConfirmOwnership(user *models.User, locale string) error
+}
+
+type emailVerificationService struct {
+	userRepo         repository.UserRepository
+	sessionRepo      repository.SessionRepository
+	verificationRepo repository.EmailVerificationRepository
+	mailer           mailer.Mailer
+	templates        *mailer.Renderer
+}
+
+func NewEmailVerificationService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, verificationRepo repository.EmailVerificationRepository, mail mailer.Mailer, templates *mailer.Renderer) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mail,
+		templates:        templates,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(user *models.User, locale string) error {
+	token, err := utils.GenerateSecureToken(emailVerificationTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	err = s.verificationRepo.Create(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(EmailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	go s.sendVerificationEmail(user.Name, user.Email, token, locale)
+	return nil
+}
+
+func (s *emailVerificationService) sendVerificationEmail(name, email, token, locale string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	link, err := appLink(config.AppConfig.EmailVerification.URL, token)
+	if err != nil {
+		log.Printf("Failed to build email verification link: %v", err)
+		return
+	}
+
+	msg, err := s.templates.Render(templateEmailVerification, locale, map[string]interface{}{
+		"Name":           name,
+		"Link":           link,
+		"ExpiresInHours": int(EmailVerificationTTL.Hours()),
+	})
+	if err != nil {
+		log.Printf("Failed to render email verification email: %v", err)
+		return
+	}
+	msg.To = email
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send email verification email: %v", err)
+	}
+}
+
+func (s *emailVerificationService) Verify(req *dto.VerifyEmailRequest) (*dto.AuthResponse, error) {
+	token, err := s.verificationRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if !token.Usable(time.Now()) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	redeemed, err := s.verificationRepo.Redeem(token)
+	if err != nil {
+		return nil, err
+	}
+	if !redeemed {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		Success: true,
+		Message: "Email verified successfully",
+		Data: &dto.AuthData{
+			User: mapUserToDTO(user),
+		},
+	}, nil
+}
+
+func (s *emailVerificationService) Resend(userID uuid.UUID, locale string) (*dto.TokenResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerified() {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	recent, err := s.verificationRepo.FindCreatedSince(userID, now.Add(-verificationResendWindow))
+	if err != nil {
+		return nil, err
+	}
+	if len(recent) > 0 {
+		if wait := recent[0].CreatedAt.Add(verificationResendInterval).Sub(now); wait > 0 {
+			return nil, &ThrottledError{RetryAfter: wait}
+		}
+	}
+	if len(recent) >= verificationResendLimit {
+		oldest := recent[verificationResendLimit-1]
+		return nil, &ThrottledError{RetryAfter: oldest.CreatedAt.Add(verificationResendWindow).Sub(now)}
+	}
+
+	if err := s.SendVerification(user, locale); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		Success: true,
+		Message: "Verification email sent",
+	}, nil
+}
+
+func (s *emailVerificationService) IsVerified(userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified(), nil
+}
+
+func (s *emailVerificationService) ConfirmOwnership(user *models.User, locale string) error {
+	if user.EmailVerified() {
+		return nil
+	}
+
+	if _, err := s.sessionRepo.RevokeOtherFamilies(user.ID, uuid.Nil); err != nil {
+		return err
+	}
+	hadPassword := user.HasPassword()
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.Password = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if hadPassword {
+		go s.sendPasswordRemovedEmail(user.Name, user.Email, locale)
+	}
+	return nil
+}
+
+func (s *emailVerificationService) sendPasswordRemovedEmail(name, email, locale string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	msg, err := s.templates.Render(templatePasswordRemoved, locale, map[string]interface{}{
+		"Name": name,
+	})
+	if err != nil {
+		log.Printf("Failed to render password removed email: %v", err)
+		return
+	}
+	msg.To = email
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send password removed email: %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/metadata"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/pagination"
+	"github.com/video-mobile-app/go-server/internal/platform"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"gorm.io/gorm"
+)
+
+type LinkService interface {
+	Create(ctx context.Context, userID uuid.UUID, req *dto.CreateLinkRequest) (*dto.CreateLinkResponse, error)
+	List(userID uuid.UUID, query *dto.ListLinksQuery) (*dto.LinkListResponse, error)
+	Search(userID uuid.UUID, query *dto.LinkSearchQuery) (*dto.LinkSearchResponse, error)
+}
+
+const (
+	maxTitleLength        = 500
+	maxAuthorLength       = 255
+	maxThumbnailURLLength = 2048
+)
+
+type linkService struct {
+	linkRepo repository.LinkRepository
+	tagRepo  repository.TagRepository
+	fetcher  metadata.Fetcher
+}
+
+func NewLinkService(linkRepo repository.LinkRepository, tagRepo repository.TagRepository, fetcher metadata.Fetcher) LinkService {
+	return &linkService{
+		linkRepo: linkRepo,
+		tagRepo:  tagRepo,
+		fetcher:  fetcher,
+	}
+}
+
+func (s *linkService) Create(ctx context.Context, userID uuid.UUID, req *dto.CreateLinkRequest) (*dto.CreateLinkResponse, error) {
+	url := strings.TrimSpace(req.URL)
+
+	match, err := platform.Resolve(url)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: "url", Message: "Please provide a valid URL"}
+	}
+
+	existing, err := s.linkRepo.FindByCanonicalURL(userID, match.CanonicalURL)
+	if err == nil && existing != nil {
+		return s.duplicate(existing, req)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// The client only knows better than URL detection when detection failed.
+	source := match.Source
+	if source == constants.SourceOther && req.Source != nil && *req.Source != "" {
+		source = *req.Source
+	}
+
+	link := &models.Link{
+		UserID:       userID,
+		URL:          url,
+		CanonicalURL: &match.CanonicalURL,
+		Source:       source,
+		Title:        trimmedOrNil(req.Title),
+		Category:     trimmedOrNil(req.Category),
+		ThumbnailURL: trimmedOrNil(req.ThumbnailURL),
+		Notes:        trimmedOrNil(req.Notes),
+	}
+
+	s.applyMetadata(ctx, link)
+
+	if err := s.linkRepo.Create(link); err != nil {
+		// A concurrent save of the same video won the race for the unique
+		// (user_id, canonical_url) index; treat this save as the duplicate.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			existing, findErr := s.linkRepo.FindByCanonicalURL(userID, match.CanonicalURL)
+			if findErr == nil {
+				return s.duplicate(existing, req)
+			}
+		}
+		return nil, err
+	}
+
+	response := &dto.CreateLinkResponse{
+		Success: true,
+		Message: "Link saved successfully",
+		Data:    mapLinkToDTO(link),
+	}
+
+	return response, nil
+}
+
+func (s *linkService) List(userID uuid.UUID, query *dto.ListLinksQuery) (*dto.LinkListResponse, error) {
+	after, err := pagination.Decode(query.Cursor)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: "cursor", Message: "Invalid cursor"}
+	}
+	limit := pagination.Limit(query.Limit)
+
+	// Fetch one extra row to learn whether another page exists.
+	links, err := s.linkRepo.FindAllByUser(userID, repository.LinkFilter{
+		Search:       query.Search,
+		Source:       query.Source,
+		Category:     query.Category,
+		Tags:         normalizeTagNames(query.Tags),
+		MatchAllTags: query.TagMode == "all",
+	}, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.Pagination{Limit: limit}
+	if len(links) > limit {
+		links = links[:limit]
+		last := links[len(links)-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		page.NextCursor = &next
+		page.HasMore = true
+	}
+
+	data := make([]dto.LinkResponse, 0, len(links))
+	for i := range links {
+		data = append(data, *mapLinkToDTO(&links[i]))
+	}
+	responses := make([]*dto.LinkResponse, 0, len(data))
+	for i := range data {
+		responses = append(responses, &data[i])
+	}
+	if err := loadTags(s.tagRepo, responses...); err != nil {
+		return nil, err
+	}
+
+	response := &dto.LinkListResponse{
+		Success:    true,
+		Message:    "Links retrieved successfully",
+		Data:       data,
+		Pagination: page,
+	}
+
+	return response, nil
+}
+
+func (s *linkService) Search(userID uuid.UUID, query *dto.LinkSearchQuery) (*dto.LinkSearchResponse, error) {
+	after, err := pagination.Decode(query.Cursor)
+	if err != nil {
+		return nil, &dto.ValidationError{Field: "cursor", Message: "Invalid cursor"}
+	}
+	limit := pagination.Limit(query.Limit)
+
+	text := strings.TrimSpace(query.Q)
+	if text == "" {
+		return nil, &dto.ValidationError{Field: "q", Message: "This field is required"}
+	}
+
+	filter := repository.LinkSearchFilter{
+		Query:    text,
+		Mode:     query.Mode,
+		Source:   query.Source,
+		Category: query.Category,
+	}
+
+	// Later pages of a fuzzy result set stay fuzzy; otherwise try word
+	// matches first and fall back to fuzzy only when the first page is empty.
+	matchType := constants.MatchTypeFullText
+	if after != nil && after.MatchType == constants.MatchTypeFuzzy {
+		matchType = constants.MatchTypeFuzzy
+	}
+
+	var rows []repository.LinkSearchRow
+	if matchType == constants.MatchTypeFullText {
+		rows, err = s.linkRepo.Search(userID, filter, after, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 && after == nil {
+			matchType = constants.MatchTypeFuzzy
+		}
+	}
+	if matchType == constants.MatchTypeFuzzy {
+		rows, err = s.linkRepo.FuzzySearch(userID, filter, config.AppConfig.Search.FuzzyThreshold, after, limit+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page := &dto.Pagination{Limit: limit}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		next := pagination.Cursor{MatchType: matchType, Rank: last.Rank, CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		page.NextCursor = &next
+		page.HasMore = true
+	}
+
+	data := make([]dto.LinkSearchResult, 0, len(rows))
+	for i := range rows {
+		data = append(data, dto.LinkSearchResult{
+			LinkResponse: *mapLinkToDTO(&rows[i].Link),
+			Rank:         rows[i].Rank,
+			Highlights: dto.LinkHighlights{
+				Title:   highlightOrNil(rows[i].TitleHighlight),
+				Snippet: highlightOrNil(rows[i].Snippet),
+			},
+		})
+	}
+	responses := make([]*dto.LinkResponse, 0, len(data))
+	for i := range data {
+		responses = append(responses, &data[i].LinkResponse)
+	}
+	if err := loadTags(s.tagRepo, responses...); err != nil {
+		return nil, err
+	}
+
+	response := &dto.LinkSearchResponse{
+		Success:    true,
+		Message:    "Search results retrieved successfully",
+		MatchType:  matchType,
+		Data:       data,
+		Pagination: page,
+	}
+
+	return response, nil
+}
+
+// duplicate builds the response for a save of an already saved video. With
+// req.Merge set, a title or category sent with the save replaces the stored
+// one; otherwise the existing link is returned untouched.
+func (s *linkService) duplicate(existing *models.Link, req *dto.CreateLinkRequest) (*dto.CreateLinkResponse, error) {
+	if req.Merge {
+		changed := false
+		if title := trimmedOrNil(req.Title); title != nil {
+			existing.Title = title
+			changed = true
+		}
+		if category := trimmedOrNil(req.Category); category != nil {
+			existing.Category = category
+			changed = true
+		}
+		if changed {
+			if err := s.linkRepo.Update(existing); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data := mapLinkToDTO(existing)
+	if err := loadTags(s.tagRepo, data); err != nil {
+		return nil, err
+	}
+
+	response := &dto.CreateLinkResponse{
+		Success:   true,
+		Message:   "Link already saved",
+		Duplicate: true,
+		Data:      data,
+	}
+
+	return response, nil
+}
+
+// applyMetadata fills fields the client left empty from the page's preview
+// metadata. Fetch failures are ignored; the link is saved without them, as
+// it is when the client goes away and ctx is cancelled. Text is cut to fit
+// its column; a thumbnail URL too long to store is dropped, since a cut
+// one would not load.
+func (s *linkService) applyMetadata(ctx context.Context, link *models.Link) {
+	if s.fetcher == nil {
+		return
+	}
+
+	meta, err := s.fetcher.Fetch(ctx, link.URL)
+	if err != nil {
+		return
+	}
+
+	if link.Title == nil && meta.Title != "" {
+		title := truncate(meta.Title, maxTitleLength)
+		link.Title = &title
+	}
+	if link.Description == nil && meta.Description != "" {
+		description := meta.Description
+		link.Description = &description
+	}
+	if link.AuthorName == nil && meta.Author != "" {
+		author := truncate(meta.Author, maxAuthorLength)
+		link.AuthorName = &author
+	}
+	if link.ThumbnailURL == nil && meta.ThumbnailURL != "" && len(meta.ThumbnailURL) <= maxThumbnailURLLength {
+		thumbnailURL := meta.ThumbnailURL
+		link.ThumbnailURL = &thumbnailURL
+	}
+	if link.DurationSeconds == nil && meta.Duration > 0 {
+		seconds := int(meta.Duration.Seconds())
+		link.DurationSeconds = &seconds
+	}
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// highlightOrNil escapes ts_headline output as HTML and wraps its matches
+// in <mark></mark>. Output that contains no match, which would otherwise
+// just repeat the start of the text, is dropped.
+func highlightOrNil(value string) *string {
+	if !strings.Contains(value, repository.HighlightStart) {
+		return nil
+	}
+	marked := highlightMarkup.Replace(html.EscapeString(value))
+	return &marked
+}
+
+var highlightMarkup = strings.NewReplacer(
+	repository.HighlightStart, "<mark>",
+	repository.HighlightStop, "</mark>",
+)
+
+func truncate(value string, maxRunes int) string {
+	runes := []rune(value)
+	if len(runes) <= maxRunes {
+		return value
+	}
+	return string(runes[:maxRunes])
+}
+
+func mapLinkToDTO(link *models.Link) *dto.LinkResponse {
+	return &dto.LinkResponse{
+		ID:              link.ID.String(),
+		URL:             link.URL,
+		Source:          link.Source,
+		Title:           link.Title,
+		Description:     link.Description,
+		AuthorName:      link.AuthorName,
+		Notes:           link.Notes,
+		Category:        link.Category,
+		ThumbnailURL:    link.ThumbnailURL,
+		DurationSeconds: link.DurationSeconds,
+		Tags:            []string{},
+		CreatedAt:       link.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/metadata"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"gorm.io/gorm"
+)
+
+func TestHighlightOrNilEscapesPageText(t *testing.T) {
+	start, stop := repository.HighlightStart, repository.HighlightStop
+
+	got := highlightOrNil(`<img src=x onerror="alert(1)"> ` + start + "Sunset" + stop + " & more")
+	want := `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>Sunset</mark> &amp; more`
+	if got == nil || *got != want {
+		t.Fatalf("highlightOrNil = %v, want %q", got, want)
+	}
+
+	if got := highlightOrNil("<mark>no match</mark>"); got != nil {
+		t.Fatalf("highlightOrNil without a match = %q, want nil", *got)
+	}
+}
+
+// fixedFetcher returns meta for every URL while ctx is live.
+type fixedFetcher struct {
+	meta *metadata.Metadata
+}
+
+func (f fixedFetcher) Fetch(ctx context.Context, _ string) (*metadata.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.meta, nil
+}
+
+func TestApplyMetadataFitsColumns(t *testing.T) {
+	links := &linkService{fetcher: fixedFetcher{&metadata.Metadata{
+		Title:        strings.Repeat("t", maxTitleLength+10),
+		ThumbnailURL: "https://example.com/" + strings.Repeat("a", maxThumbnailURLLength),
+	}}}
+
+	link := &models.Link{URL: "https://example.com/video"}
+	links.applyMetadata(context.Background(), link)
+	if link.Title == nil || len(*link.Title) != maxTitleLength {
+		t.Fatalf("title not cut to %d characters: %v", maxTitleLength, link.Title)
+	}
+	if link.ThumbnailURL != nil {
+		t.Fatalf("over-long thumbnail URL kept: %d bytes", len(*link.ThumbnailURL))
+	}
+}
+
+func TestApplyMetadataStopsWithTheRequest(t *testing.T) {
+	links := &linkService{fetcher: fixedFetcher{&metadata.Metadata{Title: "Sunset"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	link := &models.Link{URL: "https://example.com/video"}
+	links.applyMetadata(ctx, link)
+	if link.Title != nil {
+		t.Fatalf("metadata applied after the request was cancelled: %q", *link.Title)
+	}
+}
+
+// savedLinkRepo holds one saved link. With hiddenUntilCreate set, the link
+// is only found after a Create, as when a concurrent save wins the race
+// for the unique index.
+type savedLinkRepo struct {
+	repository.LinkRepository
+	saved             *models.Link
+	hiddenUntilCreate bool
+	updates           int
+}
+
+func (r *savedLinkRepo) FindByCanonicalURL(uuid.UUID, string) (*models.Link, error) {
+	if r.hiddenUntilCreate {
+		return nil, gorm.ErrRecordNotFound
+	}
+	link := *r.saved
+	return &link, nil
+}
+
+func (r *savedLinkRepo) Create(*models.Link) error {
+	r.hiddenUntilCreate = false
+	return gorm.ErrDuplicatedKey
+}
+
+func (r *savedLinkRepo) Update(link *models.Link) error {
+	r.updates++
+	r.saved = link
+	return nil
+}
+
+type noTagRepo struct {
+	repository.TagRepository
+}
+
+func (noTagRepo) FindNamesByLinks([]uuid.UUID) (map[uuid.UUID][]string, error) {
+	return map[uuid.UUID][]string{}, nil
+}
+
+func savedLink(title string) *models.Link {
+	return &models.Link{ID: uuid.New(), URL: "https://youtu.be/dQw4w9WgXcQ", Title: &title}
+}
+
+func TestCreateReturnsTheSavedLinkForADuplicate(t *testing.T) {
+	repo := &savedLinkRepo{saved: savedLink("Original")}
+	links := NewLinkService(repo, noTagRepo{}, nil)
+
+	title := "New title"
+	response, err := links.Create(context.Background(), uuid.New(), &dto.CreateLinkRequest{
+		URL:   "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Title: &title,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !response.Duplicate || response.Data.ID != repo.saved.ID.String() {
+		t.Fatalf("response = %+v, want the saved link as a duplicate", response)
+	}
+	if repo.updates != 0 || *response.Data.Title != "Original" {
+		t.Fatalf("duplicate without merge changed the link: title %q, %d updates", *response.Data.Title, repo.updates)
+	}
+}
+
+func TestCreateMergesIntoADuplicate(t *testing.T) {
+	repo := &savedLinkRepo{saved: savedLink("Original")}
+	links := NewLinkService(repo, noTagRepo{}, nil)
+
+	title, category := " New title ", "music"
+	response, err := links.Create(context.Background(), uuid.New(), &dto.CreateLinkRequest{
+		URL:      "https://youtu.be/dQw4w9WgXcQ",
+		Title:    &title,
+		Category: &category,
+		Merge:    true,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !response.Duplicate || repo.updates != 1 {
+		t.Fatalf("duplicate = %v with %d updates, want a merged duplicate", response.Duplicate, repo.updates)
+	}
+	if *repo.saved.Title != "New title" || *repo.saved.Category != "music" {
+		t.Fatalf("saved title %q, category %q", *repo.saved.Title, *repo.saved.Category)
+	}
+}
+
+func TestCreateTreatsALostRaceAsADuplicate(t *testing.T) {
+	repo := &savedLinkRepo{saved: savedLink("Original"), hiddenUntilCreate: true}
+	links := NewLinkService(repo, noTagRepo{}, nil)
+
+	response, err := links.Create(context.Background(), uuid.New(), &dto.CreateLinkRequest{
+		URL: "https://youtu.be/dQw4w9WgXcQ",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !response.Duplicate || response.Data.ID != repo.saved.ID.String() {
+		t.Fatalf("response = %+v, want the link saved concurrently", response)
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/mailer"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	magicLinkNonceBytes = 32
+	// MagicLinkTTL is how long a sign-in link stays valid.
+	MagicLinkTTL = 15 * time.Minute
+
+	// An address may be sent a sign-in link once a minute and five times
+	// an hour.
+	magicLinkInterval = time.Minute
+	magicLinkWindow   = time.Hour
+	magicLinkLimit    = 5
+
+	templateMagicLink = "magic_link"
+)
+
+var (
+	ErrInvalidMagicLink     = errors.New("sign-in link is invalid or has expired")
+	ErrMagicLinkOtherDevice = errors.New("open the sign-in link on the device you requested it from")
+)
+
+type MagicLinkService interface {
+	// Request emails a sign-in link to req.Email and returns a nonce the
+	// caller must keep, normally in a cookie: the link only works when the
+	// nonce comes back with it. It answers the same whether or not the
+	// address has an account. locale picks the email language, as a tag
+	// or Accept-Language value.
+	Request(req *dto.MagicLinkRequest, locale string) (*dto.TokenResponse, string, error)
+	// Authenticate redeems a sign-in link and returns its account. With
+	// auto-registration on, an unknown address gets a new account, and
+	// created is true. locale picks the language of any email it sends.
+	Authenticate(req *dto.MagicLinkConsumeRequest, nonce, locale string) (user *models.User, created bool, err error)
+}
+
+type magicLinkService struct {
+	userRepo      repository.UserRepository
+	verification  EmailVerificationService
+	magicLinkRepo repository.MagicLinkRepository
+	mailer        mailer.Mailer
+	templates     *mailer.Renderer
+}
+
+func NewMagicLinkService(userRepo repository.UserRepository, verification EmailVerificationService, magicLinkRepo repository.MagicLinkRepository, mail mailer.Mailer, templates *mailer.Renderer) MagicLinkService {
+	return &magicLinkService{
+		userRepo:      userRepo,
+		verification:  verification,
+		magicLinkRepo: magicLinkRepo,
+		mailer:        mail,
+		templates:     templates,
+	}
+}
+
+func (s *magicLinkService) Request(req *dto.MagicLinkRequest, locale string) (*dto.TokenResponse, string, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// Throttling goes by address, not account, so it looks the same for
+	// addresses that have none.
+	now := time.Now()
+	recent, err := s.magicLinkRepo.FindCreatedSince(email, now.Add(-magicLinkWindow))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(recent) > 0 {
+		if wait := recent[0].CreatedAt.Add(magicLinkInterval).Sub(now); wait > 0 {
+			return nil, "", &ThrottledError{RetryAfter: wait}
+		}
+	}
+	if len(recent) >= magicLinkLimit {
+		oldest := recent[magicLinkLimit-1]
+		return nil, "", &ThrottledError{RetryAfter: oldest.CreatedAt.Add(magicLinkWindow).Sub(now)}
+	}
+
+	var user *models.User
+	found, err := s.userRepo.FindByEmail(email)
+	switch {
+	case err == nil:
+		user = found
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, "", err
+	}
+
+	nonce, err := utils.GenerateSecureToken(magicLinkNonceBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &models.MagicLinkToken{
+		ID:        uuid.New(),
+		Email:     email,
+		NonceHash: utils.HashToken(nonce),
+		ExpiresAt: now.Add(MagicLinkTTL),
+	}
+	if err := s.magicLinkRepo.Create(token); err != nil {
+		return nil, "", err
+	}
+
+	response := &dto.TokenResponse{
+		Success: true,
+		Message: "If this email can sign in, a sign-in link has been sent",
+	}
+
+	if user == nil && !config.AppConfig.MagicLink.AutoRegister {
+		return response, nonce, nil
+	}
+
+	name := ""
+	if user != nil {
+		name = user.Name
+	}
+	// Every request stores a token above; signing the link and sending it
+	// happen in the background, so an address that gets an email is
+	// answered no slower than one that does not.
+	go s.sendMagicLinkEmail(name, email, token, locale)
+
+	return response, nonce, nil
+}
+
+func (s *magicLinkService) sendMagicLinkEmail(name, email string, token *models.MagicLinkToken, locale string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	linkToken, err := utils.GenerateMagicLinkToken(token.ID, email, token.ExpiresAt)
+	if err != nil {
+		log.Printf("Failed to sign sign-in link: %v", err)
+		return
+	}
+
+	link, err := appLink(config.AppConfig.MagicLink.URL, linkToken)
+	if err != nil {
+		log.Printf("Failed to build sign-in link: %v", err)
+		return
+	}
+
+	msg, err := s.templates.Render(templateMagicLink, locale, map[string]interface{}{
+		"Name":             name,
+		"Link":             link,
+		"ExpiresInMinutes": int(MagicLinkTTL.Minutes()),
+	})
+	if err != nil {
+		log.Printf("Failed to render sign-in link email: %v", err)
+		return
+	}
+	msg.To = email
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send sign-in link email: %v", err)
+	}
+}
+
+func (s *magicLinkService) Authenticate(req *dto.MagicLinkConsumeRequest, nonce, locale string) (*models.User, bool, error) {
+	claims, err := utils.ValidateMagicLinkToken(req.Token)
+	if err != nil {
+		return nil, false, ErrInvalidMagicLink
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, false, ErrInvalidMagicLink
+	}
+
+	token, err := s.magicLinkRepo.FindByID(tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrInvalidMagicLink
+		}
+		return nil, false, err
+	}
+	if token.Email != claims.Email || !token.Usable(time.Now()) {
+		return nil, false, ErrInvalidMagicLink
+	}
+
+	// A link opened elsewhere is left unspent, so the user can still
+	// open it on the right device.
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(token.NonceHash)) != 1 {
+		return nil, false, ErrMagicLinkOtherDevice
+	}
+
+	redeemed, err := s.magicLinkRepo.Redeem(token)
+	if err != nil {
+		return nil, false, err
+	}
+	if !redeemed {
+		return nil, false, ErrInvalidMagicLink
+	}
+
+	user, err := s.userRepo.FindByEmail(token.Email)
+	if err == nil {
+		if err := s.verification.ConfirmOwnership(user, locale); err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if !config.AppConfig.MagicLink.AutoRegister {
+		return nil, false, ErrInvalidMagicLink
+	}
+
+	now := time.Now()
+	user = &models.User{
+		Name:            truncate(strings.Split(token.Email, "@")[0], 100),
+		Email:           token.Email,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, false, err
+		}
+		// The address signed up some other way in the meantime.
+		user, err = s.userRepo.FindByEmail(token.Email)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.verification.ConfirmOwnership(user, locale); err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+	return user, true, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/totp"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+const (
+	recoveryCodeCount = 10
+	// Recovery codes are 10 characters of Crockford's base32, which has no
+	// look-alike letters, shown as xxxxx-xxxxx.
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+	// MFATokenTTL bounds the time between the password and the code.
+	MFATokenTTL = 5 * time.Minute
+)
+
+var (
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMFASetupNotStarted = errors.New("start two-factor setup first")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrInvalidMFAToken    = errors.New("sign-in expired, please sign in again")
+)
+
+type MFAService interface {
+	Status(userID uuid.UUID) (*dto.MFAStatusResponse, error)
+	// Setup starts enrollment with a new secret. Two-factor sign-in stays
+	// off until Confirm sees a code from it.
+	Setup(userID uuid.UUID) (*dto.MFASetupResponse, error)
+	Confirm(userID uuid.UUID, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+	Disable(userID uuid.UUID, req *dto.MFACodeRequest) (*dto.TokenResponse, error)
+	// Authenticate checks a second-factor code for user. Wrong codes count
+	// against the user across sign-in attempts, and it returns a
+	// *ThrottledError while they have failed too often.
+	Authenticate(user *models.User, code string) error
+}
+
+type mfaService struct {
+	userRepo repository.UserRepository
+	mfaRepo  repository.MFARepository
+	limiter  LoginLimiter
+}
+
+func NewMFAService(userRepo repository.UserRepository, mfaRepo repository.MFARepository, limiter LoginLimiter) MFAService {
+	return &mfaService{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		limiter:  limiter,
+	}
+}
+
+func (s *mfaService) Status(userID uuid.UUID) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &dto.MFAStatusData{Enabled: user.MFAEnabled()}
+	if data.Enabled {
+		if data.RecoveryCodesRemaining, err = s.mfaRepo.CountUnusedRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.MFAStatusResponse{
+		Success: true,
+		Message: "Two-factor status retrieved successfully",
+		Data:    data,
+	}, nil
+}
+
+func (s *mfaService) Setup(userID uuid.UUID) (*dto.MFASetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SetPendingSecret(userID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFASetupResponse{
+		Success: true,
+		Message: "Scan the code with your authenticator app, then confirm with a code from it",
+		Data: &dto.MFASetupData{
+			Secret:     secret,
+			OTPAuthURI: totp.URI(config.AppConfig.MFA.Issuer, user.Email, secret),
+		},
+	}, nil
+}
+
+func (s *mfaService) Confirm(userID uuid.UUID, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrMFASetupNotStarted
+	}
+
+	secret, err := utils.DecryptSecret(*user.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, req.Code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(userID, step, records); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFARecoveryCodesResponse{
+		Success:       true,
+		Message:       "Two-factor authentication enabled. Store these recovery codes somewhere safe; they are shown only once",
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *mfaService) Disable(userID uuid.UUID, req *dto.MFACodeRequest) (*dto.TokenResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.Authenticate(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.Disable(userID); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
+
+func (s *mfaService) Authenticate(user *models.User, code string) error {
+	if !user.MFAEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	// Codes are counted apart from passwords, so signing in again with the
+	// password does not buy more guesses.
+	subject := "mfa:" + user.ID.String()
+	wait, err := s.limiter.Begin(subject, "")
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+
+	ok, err := s.checkCode(user, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.limiter.Succeed(subject, "")
+	}
+
+	if err := s.limiter.Fail(subject, ""); err != nil {
+		return err
+	}
+	return ErrInvalidMFACode
+}
+
+// checkCode accepts an authenticator code from an unused time step or an
+// unused recovery code.
+func (s *mfaService) checkCode(user *models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		secret, err := utils.DecryptSecret(*user.TOTPSecret)
+		if err != nil {
+			return false, err
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.mfaRepo.ConsumeStep(user.ID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return false, nil
+	}
+	return s.mfaRepo.UseRecoveryCode(user.ID, utils.HashToken(normalized))
+}
+
+func newRecoveryCodes(userID uuid.UUID) ([]string, []models.MFARecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeLength)
+	for len(codes) < recoveryCodeCount {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := make([]byte, recoveryCodeLength)
+		for i, b := range buf {
+			code[i] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+
+		raw := string(code)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(raw),
+		})
+	}
+	return codes, records, nil
+}
+
+// normalizeRecoveryCode drops separators and reads look-alike letters the
+// way Crockford's base32 does.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "", "o", "0", "i", "1", "l", "1").
+		Replace(strings.ToLower(code))
+}
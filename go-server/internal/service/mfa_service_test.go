@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/lockout"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+)
+
+// recoveryCodeRepo accepts one recovery code, once.
+type recoveryCodeRepo struct {
+	repository.MFARepository
+	codeHash string
+}
+
+func (r *recoveryCodeRepo) UseRecoveryCode(_ uuid.UUID, codeHash string) (bool, error) {
+	if codeHash != r.codeHash {
+		return false, nil
+	}
+	r.codeHash = ""
+	return true, nil
+}
+
+func TestAuthenticateBacksOffWrongCodesAcrossSignIns(t *testing.T) {
+	limiter := lockout.NewTracker(lockout.NewMemoryStore(), lockout.Options{
+		Window:  time.Hour,
+		Account: lockout.Policy{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	})
+	repo := &recoveryCodeRepo{codeHash: utils.HashToken("abcde12345")}
+	mfa := NewMFAService(nil, repo, limiter)
+
+	enabledAt, secret := time.Now(), "unused"
+	user := &models.User{ID: uuid.New(), TOTPSecret: &secret, TOTPEnabledAt: &enabledAt}
+
+	for i := 0; i < 4; i++ {
+		if err := mfa.Authenticate(user, "wrong-codes"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("wrong code %d: %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	// Each sign-in gets a new MFA token, but the failures stay with the
+	// user, so even the right code has to wait.
+	var throttled *ThrottledError
+	if err := mfa.Authenticate(user, "abcde-12345"); !errors.As(err, &throttled) {
+		t.Fatalf("code after 4 failures: %v, want a *ThrottledError", err)
+	}
+	if repo.codeHash == "" {
+		t.Fatal("throttled attempt spent the recovery code")
+	}
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/passkey"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+	"gorm.io/gorm"
+)
+
+// PasskeyCeremonyTTL bounds the time between asking for passkey options
+// and answering them.
+const PasskeyCeremonyTTL = 5 * time.Minute
+
+const defaultPasskeyName = "Passkey"
+
+var (
+	ErrPasskeysDisabled         = errors.New("passkeys are not configured")
+	ErrInvalidPasskeyChallenge  = errors.New("passkey request expired, please try again")
+	ErrPasskeyRejected          = errors.New("the passkey could not be verified")
+	ErrPasskeyAlreadyRegistered = errors.New("this passkey is already registered")
+	ErrPasskeyNotFound          = errors.New("passkey not found")
+)
+
+type PasskeyService interface {
+	List(userID uuid.UUID) (*dto.PasskeyListResponse, error)
+	// RegistrationOptions starts adding a passkey to the user's account.
+	RegistrationOptions(userID uuid.UUID) (*dto.PasskeyOptionsResponse, error)
+	Register(userID uuid.UUID, req *dto.PasskeyRegisterRequest) (*dto.PasskeyCreatedResponse, error)
+	Delete(userID, id uuid.UUID) error
+	// LoginOptions starts a passkey sign-in; see Authenticate.
+	LoginOptions() (*dto.PasskeyOptionsResponse, error)
+	// Authenticate checks a passkey sign-in and returns the account the
+	// passkey belongs to.
+	Authenticate(req *dto.PasskeyLoginRequest) (*models.User, error)
+}
+
+type passkeyService struct {
+	userRepo     repository.UserRepository
+	passkeyRepo  repository.PasskeyRepository
+	relyingParty *passkey.RelyingParty
+}
+
+// NewPasskeyService builds the passkey service. relyingParty may be nil,
+// which turns passkeys off.
+func NewPasskeyService(userRepo repository.UserRepository, passkeyRepo repository.PasskeyRepository, relyingParty *passkey.RelyingParty) PasskeyService {
+	return &passkeyService{
+		userRepo:     userRepo,
+		passkeyRepo:  passkeyRepo,
+		relyingParty: relyingParty,
+	}
+}
+
+func (s *passkeyService) List(userID uuid.UUID) (*dto.PasskeyListResponse, error) {
+	credentials, err := s.passkeyRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.PasskeyResponse, 0, len(credentials))
+	for i := range credentials {
+		data = append(data, mapPasskeyToDTO(&credentials[i]))
+	}
+
+	return &dto.PasskeyListResponse{
+		Success: true,
+		Message: "Passkeys retrieved successfully",
+		Data:    data,
+	}, nil
+}
+
+func (s *passkeyService) RegistrationOptions(userID uuid.UUID) (*dto.PasskeyOptionsResponse, error) {
+	if s.relyingParty == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	user, err := s.passkeyUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, session, err := s.relyingParty.BeginRegistration(user)
+	if err != nil {
+		return nil, err
+	}
+	sessionToken, err := utils.GeneratePasskeyToken(&utils.PasskeyClaims{
+		Ceremony:  utils.PasskeyCeremonyRegistration,
+		Challenge: session.Challenge,
+		UserID:    userID,
+	}, PasskeyCeremonyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PasskeyOptionsResponse{
+		Success: true,
+		Message: "Create the passkey, then send it back with the session token",
+		Data: &dto.PasskeyOptionsData{
+			Options:      options,
+			SessionToken: sessionToken,
+		},
+	}, nil
+}
+
+func (s *passkeyService) Register(userID uuid.UUID, req *dto.PasskeyRegisterRequest) (*dto.PasskeyCreatedResponse, error) {
+	if s.relyingParty == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	claims, err := s.spendCeremony(req.SessionToken, utils.PasskeyCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if claims.UserID != userID {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+
+	user, err := s.passkeyUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.relyingParty.FinishRegistration(user, &passkey.Session{
+		Challenge:  claims.Challenge,
+		UserHandle: user.Handle,
+	}, req.Credential)
+	if err != nil {
+		if errors.Is(err, passkey.ErrInvalidResponse) || errors.Is(err, passkey.ErrUnsupportedAttestation) {
+			log.Printf("Passkey registration rejected: %v", err)
+			return nil, ErrPasskeyRejected
+		}
+		return nil, err
+	}
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	aaguid, _ := uuid.FromBytes(created.AAGUID)
+
+	credential := &models.WebAuthnCredential{
+		UserID:            userID,
+		CredentialID:      created.ID,
+		PublicKey:         created.PublicKey,
+		SignCount:         int64(created.SignCount),
+		AAGUID:            aaguid,
+		AttestationFormat: created.AttestationFormat,
+		Transports:        strings.Join(created.Transports, ","),
+		BackupEligible:    created.BackupEligible,
+		BackupState:       created.BackupState,
+		Name:              truncate(name, 100),
+	}
+	if err := s.passkeyRepo.Create(credential); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrPasskeyAlreadyRegistered
+		}
+		return nil, err
+	}
+
+	data := mapPasskeyToDTO(credential)
+	return &dto.PasskeyCreatedResponse{
+		Success: true,
+		Message: "Passkey added successfully",
+		Data:    &data,
+	}, nil
+}
+
+func (s *passkeyService) Delete(userID, id uuid.UUID) error {
+	deleted, err := s.passkeyRepo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (s *passkeyService) LoginOptions() (*dto.PasskeyOptionsResponse, error) {
+	if s.relyingParty == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	options, session, err := s.relyingParty.BeginLogin()
+	if err != nil {
+		return nil, err
+	}
+	sessionToken, err := utils.GeneratePasskeyToken(&utils.PasskeyClaims{
+		Ceremony:  utils.PasskeyCeremonyLogin,
+		Challenge: session.Challenge,
+	}, PasskeyCeremonyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PasskeyOptionsResponse{
+		Success: true,
+		Message: "Sign in with the passkey, then send it back with the session token",
+		Data: &dto.PasskeyOptionsData{
+			Options:      options,
+			SessionToken: sessionToken,
+		},
+	}, nil
+}
+
+func (s *passkeyService) Authenticate(req *dto.PasskeyLoginRequest) (*models.User, error) {
+	if s.relyingParty == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	claims, err := s.spendCeremony(req.SessionToken, utils.PasskeyCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored *models.WebAuthnCredential
+	var owner *models.User
+	lookup := func(credentialID, userHandle []byte) (*passkey.User, error) {
+		credential, err := s.passkeyRepo.FindByCredentialID(credentialID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, passkey.ErrUnknownCredential
+			}
+			return nil, err
+		}
+		if !bytes.Equal(userHandle, credential.UserID[:]) {
+			return nil, passkey.ErrUnknownCredential
+		}
+		user, err := s.userRepo.FindByID(credential.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		stored, owner = credential, user
+		return &passkey.User{
+			Handle:      user.ID[:],
+			Name:        user.Email,
+			DisplayName: user.Name,
+			Credentials: []passkey.Credential{toPasskeyCredential(credential)},
+		}, nil
+	}
+
+	_, used, err := s.relyingParty.FinishLogin(&passkey.Session{Challenge: claims.Challenge}, req.Credential, lookup)
+	if err != nil {
+		switch {
+		case errors.Is(err, passkey.ErrSignCount):
+			log.Printf("Passkey %s reported a signature counter that did not increase; it may be cloned", stored.ID)
+			return nil, ErrPasskeyRejected
+		case errors.Is(err, passkey.ErrUnknownCredential),
+			errors.Is(err, passkey.ErrInvalidResponse):
+			return nil, ErrPasskeyRejected
+		}
+		return nil, err
+	}
+
+	recorded, err := s.passkeyRepo.RecordUse(stored.ID, stored.SignCount, int64(used.SignCount), used.BackupState)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		// Another sign-in with this passkey finished first.
+		return nil, ErrPasskeyRejected
+	}
+
+	return owner, nil
+}
+
+// spendCeremony checks a passkey session token and makes sure it is never
+// accepted again, whether or not the ceremony succeeds.
+func (s *passkeyService) spendCeremony(sessionToken, ceremony string) (*utils.PasskeyClaims, error) {
+	claims, err := utils.ValidatePasskeyToken(sessionToken, ceremony)
+	if err != nil {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+	if err := utils.RevokePasskeyToken(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// passkeyUser describes an account and its registered passkeys to the
+// relying party. The user handle is the account ID, which carries no
+// personal data.
+func (s *passkeyService) passkeyUser(userID uuid.UUID) (*passkey.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.passkeyRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]passkey.Credential, 0, len(stored))
+	for i := range stored {
+		credentials = append(credentials, toPasskeyCredential(&stored[i]))
+	}
+
+	return &passkey.User{
+		Handle:      user.ID[:],
+		Name:        user.Email,
+		DisplayName: user.Name,
+		Credentials: credentials,
+	}, nil
+}
+
+func toPasskeyCredential(credential *models.WebAuthnCredential) passkey.Credential {
+	var transports []string
+	if credential.Transports != "" {
+		transports = strings.Split(credential.Transports, ",")
+	}
+	return passkey.Credential{
+		ID:                credential.CredentialID,
+		PublicKey:         credential.PublicKey,
+		SignCount:         uint32(credential.SignCount),
+		AAGUID:            credential.AAGUID[:],
+		AttestationFormat: credential.AttestationFormat,
+		Transports:        transports,
+		BackupEligible:    credential.BackupEligible,
+		BackupState:       credential.BackupState,
+	}
+}
+
+func mapPasskeyToDTO(credential *models.WebAuthnCredential) dto.PasskeyResponse {
+	transports := []string{}
+	if credential.Transports != "" {
+		transports = strings.Split(credential.Transports, ",")
+	}
+	return dto.PasskeyResponse{
+		ID:             credential.ID.String(),
+		Name:           credential.Name,
+		Transports:     transports,
+		BackupEligible: credential.BackupEligible,
+		BackupState:    credential.BackupState,
+		CreatedAt:      credential.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		LastUsedAt:     formatTimeOrNil(credential.LastUsedAt),
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/mailer"
+	"github.com/video-mobile-app/go-server/internal/models"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"github.com/video-mobile-app/go-server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetTokenBytes = 32
+	// PasswordResetTTL is how long a reset link stays valid.
+	PasswordResetTTL = 30 * time.Minute
+	mailSendTimeout  = 30 * time.Second
+
+	templatePasswordReset = "password_reset"
+)
+
+var ErrInvalidResetToken = errors.New("reset link is invalid or has expired")
+
+type PasswordService interface {
+	// ForgotPassword emails a reset link when the address belongs to an
+	// account. It succeeds either way so callers cannot probe for accounts.
+	// locale picks the email language, as a tag or Accept-Language value.
+	ForgotPassword(req *dto.ForgotPasswordRequest, locale string) (*dto.TokenResponse, error)
+	// ResetPassword sets a new password from a reset link and signs the
+	// user out everywhere.
+	ResetPassword(req *dto.ResetPasswordRequest) (*dto.TokenResponse, error)
+}
+
+type passwordService struct {
+	userRepo  repository.UserRepository
+	resetRepo repository.PasswordResetRepository
+	mailer    mailer.Mailer
+	templates *mailer.Renderer
+}
+
+func NewPasswordService(userRepo repository.UserRepository, resetRepo repository.PasswordResetRepository, mail mailer.Mailer, templates *mailer.Renderer) PasswordService {
+	return &passwordService{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		mailer:    mail,
+		templates: templates,
+	}
+}
+
+func (s *passwordService) ForgotPassword(req *dto.ForgotPasswordRequest, locale string) (*dto.TokenResponse, error) {
+	response := &dto.TokenResponse{
+		Success: true,
+		Message: "If an account exists for this email, a reset link has been sent",
+	}
+
+	user, err := s.userRepo.FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, nil
+		}
+		return nil, err
+	}
+
+	// The token is stored and sent in the background, so answering for an
+	// existing account takes no more work than for an unknown email.
+	go s.sendResetEmail(user, locale)
+
+	return response, nil
+}
+
+// sendResetEmail issues a reset token for user and emails them the link.
+func (s *passwordService) sendResetEmail(user *models.User, locale string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	token, err := utils.GenerateSecureToken(passwordResetTokenBytes)
+	if err != nil {
+		log.Printf("Failed to generate password reset token: %v", err)
+		return
+	}
+
+	err = s.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(PasswordResetTTL),
+	})
+	if err != nil {
+		log.Printf("Failed to store password reset token: %v", err)
+		return
+	}
+
+	link, err := appLink(config.AppConfig.Password.ResetURL, token)
+	if err != nil {
+		log.Printf("Failed to build password reset link: %v", err)
+		return
+	}
+
+	msg, err := s.templates.Render(templatePasswordReset, locale, map[string]interface{}{
+		"Name":             user.Name,
+		"Link":             link,
+		"ExpiresInMinutes": int(PasswordResetTTL.Minutes()),
+	})
+	if err != nil {
+		log.Printf("Failed to render password reset email: %v", err)
+		return
+	}
+	msg.To = user.Email
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+}
+
+// appLink adds token to an app screen URL as the "token" query parameter.
+func appLink(screenURL, token string) (string, error) {
+	link, err := url.Parse(screenURL)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+func (s *passwordService) ResetPassword(req *dto.ResetPasswordRequest) (*dto.TokenResponse, error) {
+	token, err := s.resetRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	if !token.Usable(time.Now()) {
+		return nil, ErrInvalidResetToken
+	}
+
+	var user models.User
+	if err := user.SetPassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	redeemed, err := s.resetRepo.Redeem(token, *user.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !redeemed {
+		return nil, ErrInvalidResetToken
+	}
+
+	return &dto.TokenResponse{
+		Success: true,
+		Message: "Password has been reset. Please sign in with your new password",
+	}, nil
+}
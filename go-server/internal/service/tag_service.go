@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/dto"
+	"github.com/video-mobile-app/go-server/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTagNameLength      = 50
+	defaultTagSuggestions = 10
+)
+
+var (
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrLinkTagNotFound = errors.New("tag is not on this link")
+)
+
+type TagService interface {
+	AddToLink(userID, linkID uuid.UUID, req *dto.AddLinkTagsRequest) (*dto.LinkDetailResponse, error)
+	RemoveFromLink(userID, linkID, tagID uuid.UUID) (*dto.LinkDetailResponse, error)
+	Autocomplete(userID uuid.UUID, query *dto.TagAutocompleteQuery) (*dto.TagListResponse, error)
+	Rename(userID, tagID uuid.UUID, req *dto.RenameTagRequest) (*dto.TagDetailResponse, error)
+}
+
+type tagService struct {
+	tagRepo  repository.TagRepository
+	linkRepo repository.LinkRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository, linkRepo repository.LinkRepository) TagService {
+	return &tagService{
+		tagRepo:  tagRepo,
+		linkRepo: linkRepo,
+	}
+}
+
+// NormalizeTagName lowercases a tag, drops a leading '#' and collapses
+// whitespace, so "#Street  Food" and "street food" are the same tag.
+func NormalizeTagName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, "#")
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	return truncate(name, maxTagNameLength)
+}
+
+// normalizeTagNames normalizes and de-duplicates names, dropping empty ones.
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, part := range strings.Split(name, ",") {
+			tag := NormalizeTagName(part)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func (s *tagService) AddToLink(userID, linkID uuid.UUID, req *dto.AddLinkTagsRequest) (*dto.LinkDetailResponse, error) {
+	link, err := s.linkRepo.FindByID(linkID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+
+	names := normalizeTagNames(req.Tags)
+	if len(names) == 0 {
+		return nil, &dto.ValidationError{Field: "tags", Message: "This field is required"}
+	}
+
+	tags, err := s.tagRepo.FindOrCreate(userID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]uuid.UUID, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := s.tagRepo.AttachToLink(link.ID, tagIDs); err != nil {
+		return nil, err
+	}
+
+	data := mapLinkToDTO(link)
+	if err := loadTags(s.tagRepo, data); err != nil {
+		return nil, err
+	}
+
+	response := &dto.LinkDetailResponse{
+		Success: true,
+		Message: "Tags added successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+func (s *tagService) RemoveFromLink(userID, linkID, tagID uuid.UUID) (*dto.LinkDetailResponse, error) {
+	link, err := s.linkRepo.FindByID(linkID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, err
+	}
+
+	removed, err := s.tagRepo.DetachFromLink(link.ID, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrLinkTagNotFound
+	}
+
+	data := mapLinkToDTO(link)
+	if err := loadTags(s.tagRepo, data); err != nil {
+		return nil, err
+	}
+
+	response := &dto.LinkDetailResponse{
+		Success: true,
+		Message: "Tag removed successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+func (s *tagService) Autocomplete(userID uuid.UUID, query *dto.TagAutocompleteQuery) (*dto.TagListResponse, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultTagSuggestions
+	}
+
+	tags, err := s.tagRepo.Autocomplete(userID, NormalizeTagName(query.Prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		data = append(data, dto.TagResponse{
+			ID:         tag.ID.String(),
+			Name:       tag.Name,
+			UsageCount: tag.UsageCount,
+		})
+	}
+
+	response := &dto.TagListResponse{
+		Success: true,
+		Message: "Tags retrieved successfully",
+		Data:    data,
+	}
+
+	return response, nil
+}
+
+// Rename changes a tag's name on all of the user's links. Renaming to the
+// name of another existing tag merges the two into that tag.
+func (s *tagService) Rename(userID, tagID uuid.UUID, req *dto.RenameTagRequest) (*dto.TagDetailResponse, error) {
+	tag, err := s.tagRepo.FindByID(tagID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	name := NormalizeTagName(req.Name)
+	if name == "" {
+		return nil, &dto.ValidationError{Field: "name", Message: "This field is required"}
+	}
+
+	response := &dto.TagDetailResponse{
+		Success: true,
+		Message: "Tag renamed successfully",
+	}
+
+	target, err := s.tagRepo.FindByName(userID, name)
+	switch {
+	case err == nil && target.ID != tag.ID:
+		if err := s.tagRepo.Merge(tag.ID, target.ID); err != nil {
+			return nil, err
+		}
+		tag = target
+		response.Message = "Tags merged successfully"
+		response.Merged = true
+	case err == nil:
+		// Renamed to its own name; nothing to do.
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		tag.Name = name
+		if err := s.tagRepo.Update(tag); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	usageCount, err := s.tagRepo.CountLinks(tag.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Data = &dto.TagResponse{
+		ID:         tag.ID.String(),
+		Name:       tag.Name,
+		UsageCount: usageCount,
+	}
+
+	return response, nil
+}
+
+// loadTags fills Tags on each link response with the link's tag names.
+func loadTags(tagRepo repository.TagRepository, links ...*dto.LinkResponse) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(links))
+	for _, link := range links {
+		if id, err := uuid.Parse(link.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	names, err := tagRepo.FindNamesByLinks(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if id, err := uuid.Parse(link.ID); err == nil && names[id] != nil {
+			link.Tags = names[id]
+		}
+	}
+	return nil
+}
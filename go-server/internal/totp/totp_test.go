@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTPMatchesRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	key, err := decodeSecret(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0))
+		if got := hotp(key, uint64(step), 8); got != tt.want {
+			t.Errorf("hotp at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAcceptsNeighbouringSteps(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, err := Code(rfcSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matched, ok := Validate(rfcSecret, code, now)
+		if !ok || matched != step+offset {
+			t.Errorf("Validate(code for step %+d) = %d, %v", offset, matched, ok)
+		}
+	}
+
+	old, _ := Code(rfcSecret, step-2)
+	if _, ok := Validate(rfcSecret, old, now); ok {
+		t.Error("Validate accepted a code two steps old")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now); ok {
+		t.Error("Validate accepted a short code")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("generated secret does not decode: %v", err)
+	}
+
+	uri := URI("Video Vault", "ana@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Video%20Vault:ana@example.com?") ||
+		!strings.Contains(uri, "secret="+secret) || !strings.Contains(uri, "issuer=Video+Vault") {
+		t.Errorf("unexpected URI %s", uri)
+	}
+}
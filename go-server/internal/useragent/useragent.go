@@ -0,0 +1,88 @@
+// Package useragent turns User-Agent headers into short device names for
+// people to recognise their sessions by.
+package useragent
+
+import "strings"
+
+const unknownDevice = "Unknown device"
+
+type rule struct {
+	token string
+	name  string
+}
+
+// Order matters: iPad and Android UAs also mention Mac OS X and Linux, and
+// most browsers also claim to be Safari.
+var devices = []rule{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"CrOS", "Chromebook"},
+	{"Windows", "Windows"},
+	{"Macintosh", "Mac"},
+	{"Linux", "Linux"},
+}
+
+var browsers = []rule{
+	{"Edg", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// DeviceName describes the client behind a User-Agent, such as
+// "Chrome on Mac", "iPhone app" or "Android tablet".
+func DeviceName(ua string) string {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return unknownDevice
+	}
+
+	// Native HTTP stacks used by the mobile app.
+	switch {
+	case strings.Contains(ua, "CFNetwork") || strings.Contains(ua, "Darwin/"):
+		if strings.Contains(ua, "iPad") {
+			return "iPad app"
+		}
+		return "iPhone app"
+	case strings.HasPrefix(ua, "okhttp/"):
+		return "Android app"
+	}
+
+	device := match(ua, devices)
+	if device == "Android" {
+		device = "Android tablet"
+		if strings.Contains(ua, "Mobile") {
+			device = "Android phone"
+		}
+	}
+
+	browser := ""
+	if strings.HasPrefix(ua, "Mozilla/") {
+		browser = match(ua, browsers)
+	}
+
+	switch {
+	case browser != "" && device != "":
+		return browser + " on " + device
+	case browser != "":
+		return browser
+	case device != "":
+		return device
+	default:
+		return unknownDevice
+	}
+}
+
+func match(ua string, rules []rule) string {
+	for _, r := range rules {
+		if strings.Contains(ua, r.token) {
+			return r.name
+		}
+	}
+	return ""
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/video-mobile-app/go-server/internal/config"
 )
@@ -53,6 +52,64 @@ func ClearAuthCookies(w http.ResponseWriter) {
 	})
 }
 
+// SetOAuthStateCookie stores a sign-in attempt until the provider redirects
+// back. Apple posts its callback cross-site, so in production the cookie is
+// SameSite=None.
+func SetOAuthStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	isProduction := config.AppConfig.Server.Env == "production"
+
+	sameSite := http.SameSiteLaxMode
+	if isProduction {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_state",
+		Value:    value,
+		HttpOnly: true,
+		Secure:   isProduction,
+		SameSite: sameSite,
+		MaxAge:   maxAge,
+		Path:     "/api/auth/oauth",
+	})
+}
+
+func ClearOAuthStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_state",
+		Value:    "",
+		HttpOnly: true,
+		MaxAge:   -1,
+		Path:     "/api/auth/oauth",
+	})
+}
+
+// SetMagicLinkNonceCookie binds a sign-in link to the device that asked
+// for it: the link only works alongside this cookie.
+func SetMagicLinkNonceCookie(w http.ResponseWriter, value string, maxAge int) {
+	isProduction := config.AppConfig.Server.Env == "production"
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "magic_link_nonce",
+		Value:    value,
+		HttpOnly: true,
+		Secure:   isProduction,
+		SameSite: getSameSite(isProduction),
+		MaxAge:   maxAge,
+		Path:     "/api/auth/magic-link",
+	})
+}
+
+func ClearMagicLinkNonceCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "magic_link_nonce",
+		Value:    "",
+		HttpOnly: true,
+		MaxAge:   -1,
+		Path:     "/api/auth/magic-link",
+	})
+}
+
 func getSameSite(isProduction bool) http.SameSite {
 	if isProduction {
 		return http.SameSiteStrictMode
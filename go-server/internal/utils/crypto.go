@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+)
+
+const encryptedPrefix = "v1:"
+
+var ErrInvalidCiphertext = errors.New("invalid encrypted value")
+
+// EncryptSecret seals a secret that must be stored but read back later,
+// such as a TOTP key, with AES-256-GCM.
+func EncryptSecret(plaintext string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func DecryptSecret(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return "", ErrInvalidCiphertext
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// secretCipher uses MFA_ENCRYPTION_KEY (32 bytes, base64) when set, and
+// otherwise derives a key from the JWT secret.
+func secretCipher() (cipher.AEAD, error) {
+	var key []byte
+	if encoded := config.AppConfig.MFA.EncryptionKey; encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("MFA_ENCRYPTION_KEY must be 32 bytes encoded as base64")
+		}
+		key = decoded
+	} else {
+		sum := sha256.Sum256([]byte(config.AppConfig.JWT.Secret + ":secret-encryption"))
+		key = sum[:]
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
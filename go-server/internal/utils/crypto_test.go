@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/video-mobile-app/go-server/internal/config"
+)
+
+func TestEncryptSecretRoundTrip(t *testing.T) {
+	setupJWT(t)
+
+	for _, key := range []string{"", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))} {
+		config.AppConfig.MFA.EncryptionKey = key
+
+		sealed, err := EncryptSecret("JBSWY3DPEHPK3PXP")
+		if err != nil {
+			t.Fatalf("EncryptSecret: %v", err)
+		}
+		if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+			t.Fatal("ciphertext contains the plaintext")
+		}
+
+		plaintext, err := DecryptSecret(sealed)
+		if err != nil || plaintext != "JBSWY3DPEHPK3PXP" {
+			t.Fatalf("DecryptSecret = %q, %v", plaintext, err)
+		}
+	}
+}
+
+func TestDecryptSecretRejectsTampering(t *testing.T) {
+	setupJWT(t)
+
+	sealed, err := EncryptSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, encryptedPrefix))
+	raw[len(raw)-1] ^= 1
+	tampered := encryptedPrefix + base64.RawStdEncoding.EncodeToString(raw)
+
+	if _, err := DecryptSecret(tampered); err == nil {
+		t.Error("DecryptSecret accepted a modified ciphertext")
+	}
+
+	config.AppConfig.JWT.Secret = "another-secret"
+	if _, err := DecryptSecret(sealed); err == nil {
+		t.Error("DecryptSecret succeeded with a different key")
+	}
+}
@@ -1,26 +1,54 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/revocation"
 )
 
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+var (
+	revocationStore revocation.Store
+	sessionStore    revocation.SessionStore
+)
+
+// SetRevocationStore sets the store ValidateToken checks token IDs against.
+func SetRevocationStore(store revocation.Store) {
+	revocationStore = store
+}
+
+// SetSessionStore sets the store ValidateToken checks the session of access
+// tokens against.
+func SetSessionStore(store revocation.SessionStore) {
+	sessionStore = store
+}
+
 type Claims struct {
 	UserID uuid.UUID `json:"sub"`
 	Email  string    `json:"email"`
+	// Role is the user's role when the token was issued; see package rbac.
+	Role string `json:"role"`
+	// SessionID is the models.Session the token was issued for.
+	SessionID uuid.UUID `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID uuid.UUID, email string) (string, error) {
+func GenerateAccessToken(userID uuid.UUID, email, role string, sessionID uuid.UUID) (string, error) {
 	cfg := config.AppConfig.JWT
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.ExpiresIn)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -30,13 +58,16 @@ func GenerateAccessToken(userID uuid.UUID, email string) (string, error) {
 	return token.SignedString([]byte(cfg.Secret))
 }
 
-func GenerateRefreshToken(userID uuid.UUID, email string) (string, error) {
+func GenerateRefreshToken(userID uuid.UUID, email, role string, sessionID uuid.UUID) (string, error) {
 	cfg := config.AppConfig.JWT
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.RefreshExpires)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -62,9 +93,259 @@ func ValidateToken(tokenString string, isRefresh bool) (*Claims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	// Tokens without an ID predate revocation and could never be revoked.
+	if claims.ID == "" {
+		return nil, ErrTokenRevoked
+	}
+	// Tokens without a role predate roles, when every user was a user.
+	if claims.Role == "" {
+		claims.Role = constants.RoleUser
+	}
+	if revocationStore != nil {
+		revoked, err := revocationStore.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	// Refresh tokens are checked against their session when they are used.
+	if !isRefresh && sessionStore != nil && claims.SessionID != uuid.Nil {
+		revoked, err := sessionStore.IsSessionRevoked(claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
 	}
 
-	return nil, jwt.ErrSignatureInvalid
+	return claims, nil
+}
+
+// RevokeToken blocks the token's ID until it expires.
+func RevokeToken(claims *Claims) error {
+	if revocationStore == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return revocationStore.Revoke(claims.ID, claims.ExpiresAt.Time)
+}
+
+// OAuthStateClaims carry one social sign-in attempt from the start
+// redirect to the provider's callback, in a signed cookie.
+type OAuthStateClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// oauthStateKey keeps state tokens from ever validating as access tokens.
+func oauthStateKey() []byte {
+	return []byte(config.AppConfig.JWT.Secret + ":oauth-state")
+}
+
+func GenerateOAuthStateToken(claims *OAuthStateClaims, ttl time.Duration) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(oauthStateKey())
+}
+
+func ValidateOAuthStateToken(tokenString string) (*OAuthStateClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OAuthStateClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return oauthStateKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*OAuthStateClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return claims, nil
+}
+
+// MFAClaims identify a user who passed the first sign-in step and still
+// owes a second factor.
+type MFAClaims struct {
+	UserID uuid.UUID `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// mfaKey keeps MFA tokens from ever validating as access tokens.
+func mfaKey() []byte {
+	return []byte(config.AppConfig.JWT.Secret + ":mfa")
+}
+
+func GenerateMFAToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	claims := &MFAClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(mfaKey())
+}
+
+func ValidateMFAToken(tokenString string) (*MFAClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &MFAClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return mfaKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*MFAClaims)
+	if !ok || !token.Valid || claims.ID == "" {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	if revocationStore != nil {
+		revoked, err := revocationStore.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return claims, nil
+}
+
+// RevokeMFAToken makes an MFA token unusable, once it has been used or
+// has seen too many wrong codes.
+func RevokeMFAToken(claims *MFAClaims) error {
+	if revocationStore == nil || claims.ExpiresAt == nil {
+		return nil
+	}
+	return revocationStore.Revoke(claims.ID, claims.ExpiresAt.Time)
+}
+
+// Passkey ceremonies a PasskeyClaims token can belong to.
+const (
+	PasskeyCeremonyRegistration = "registration"
+	PasskeyCeremonyLogin        = "login"
+)
+
+// PasskeyClaims carry the challenge of one passkey ceremony from its
+// options request to the authenticator's response.
+type PasskeyClaims struct {
+	Ceremony  string `json:"ceremony"`
+	Challenge string `json:"challenge"`
+	// UserID is the account a registration adds a passkey to; it is
+	// uuid.Nil for sign-ins.
+	UserID uuid.UUID `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// passkeyKey keeps passkey tokens from ever validating as access tokens.
+func passkeyKey() []byte {
+	return []byte(config.AppConfig.JWT.Secret + ":passkey")
+}
+
+func GeneratePasskeyToken(claims *PasskeyClaims, ttl time.Duration) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(passkeyKey())
+}
+
+// ValidatePasskeyToken checks a passkey token was issued for ceremony and
+// has not been used.
+func ValidatePasskeyToken(tokenString, ceremony string) (*PasskeyClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &PasskeyClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return passkeyKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*PasskeyClaims)
+	if !ok || !token.Valid || claims.ID == "" || claims.Ceremony != ceremony {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	if revocationStore != nil {
+		revoked, err := revocationStore.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return claims, nil
+}
+
+// RevokePasskeyToken spends a passkey token so its challenge cannot be
+// answered twice.
+func RevokePasskeyToken(claims *PasskeyClaims) error {
+	if revocationStore == nil || claims.ExpiresAt == nil {
+		return nil
+	}
+	return revocationStore.Revoke(claims.ID, claims.ExpiresAt.Time)
+}
+
+// MagicLinkClaims identify one emailed sign-in link. The token ID is the
+// stored link, which records whether it was used.
+type MagicLinkClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// magicLinkKey keeps sign-in links from ever validating as access tokens.
+func magicLinkKey() []byte {
+	return []byte(config.AppConfig.JWT.Secret + ":magic-link")
+}
+
+func GenerateMagicLinkToken(id uuid.UUID, email string, expiresAt time.Time) (string, error) {
+	claims := &MagicLinkClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(magicLinkKey())
+}
+
+func ValidateMagicLinkToken(tokenString string) (*MagicLinkClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &MagicLinkClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return magicLinkKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*MagicLinkClaims)
+	if !ok || !token.Valid || claims.Email == "" {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return claims, nil
 }
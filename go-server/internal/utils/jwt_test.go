@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/video-mobile-app/go-server/internal/config"
+	"github.com/video-mobile-app/go-server/internal/constants"
+	"github.com/video-mobile-app/go-server/internal/revocation"
+)
+
+func setupJWT(t *testing.T) {
+	t.Helper()
+	previousConfig, previousStore, previousSessions := config.AppConfig, revocationStore, sessionStore
+	t.Cleanup(func() {
+		config.AppConfig = previousConfig
+		revocationStore = previousStore
+		sessionStore = previousSessions
+	})
+
+	config.AppConfig = &config.Config{
+		JWT: config.JWTConfig{
+			Secret:         "test-secret",
+			RefreshSecret:  "test-refresh-secret",
+			ExpiresIn:      time.Hour,
+			RefreshExpires: 24 * time.Hour,
+		},
+	}
+	SetRevocationStore(revocation.NewMemoryStore())
+}
+
+func TestValidateTokenRejectsRevokedTokens(t *testing.T) {
+	setupJWT(t)
+	userID, sessionID := uuid.New(), uuid.New()
+
+	revoked, err := GenerateAccessToken(userID, "a@example.com", constants.RoleUser, sessionID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	other, err := GenerateAccessToken(userID, "a@example.com", constants.RoleUser, sessionID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+
+	claims, err := ValidateToken(revoked, false)
+	if err != nil {
+		t.Fatalf("ValidateToken before revocation: %v", err)
+	}
+	if claims.ID == "" {
+		t.Fatal("access token has no jti")
+	}
+
+	if err := RevokeToken(claims); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+
+	if _, err := ValidateToken(revoked, false); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("ValidateToken after revocation = %v; want ErrTokenRevoked", err)
+	}
+	if _, err := ValidateToken(other, false); err != nil {
+		t.Fatalf("ValidateToken for another token of the same user: %v", err)
+	}
+}
+
+type revokedSessions map[uuid.UUID]bool
+
+func (s revokedSessions) IsSessionRevoked(sessionID uuid.UUID) (bool, error) {
+	return s[sessionID], nil
+}
+
+func TestValidateTokenRejectsAccessTokensOfRevokedSessions(t *testing.T) {
+	setupJWT(t)
+	revoked := revokedSessions{}
+	SetSessionStore(revoked)
+	userID, sessionID := uuid.New(), uuid.New()
+
+	access, _ := GenerateAccessToken(userID, "a@example.com", constants.RoleUser, sessionID)
+	refresh, _ := GenerateRefreshToken(userID, "a@example.com", constants.RoleUser, sessionID)
+	if _, err := ValidateToken(access, false); err != nil {
+		t.Fatalf("ValidateToken before the session was revoked: %v", err)
+	}
+
+	revoked[sessionID] = true
+	if _, err := ValidateToken(access, false); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("ValidateToken after the session was revoked = %v; want ErrTokenRevoked", err)
+	}
+	// The session row itself decides whether a refresh token still works.
+	if _, err := ValidateToken(refresh, true); err != nil {
+		t.Fatalf("ValidateToken for the refresh token: %v", err)
+	}
+}
+
+func TestAccessTokenCarriesRole(t *testing.T) {
+	setupJWT(t)
+
+	token, _ := GenerateAccessToken(uuid.New(), "a@example.com", constants.RoleAdmin, uuid.New())
+	claims, err := ValidateToken(token, false)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.Role != constants.RoleAdmin {
+		t.Fatalf("Role = %q, want %q", claims.Role, constants.RoleAdmin)
+	}
+
+	// A token signed before roles were added has no role claim.
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID: uuid.New(),
+		Email:  "a@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte(config.AppConfig.JWT.Secret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	claims, err = ValidateToken(legacy, false)
+	if err != nil {
+		t.Fatalf("ValidateToken for a token without a role: %v", err)
+	}
+	if claims.Role != constants.RoleUser {
+		t.Fatalf("Role of a token without one = %q, want %q", claims.Role, constants.RoleUser)
+	}
+}
+
+func TestRefreshTokensHaveUniqueIDs(t *testing.T) {
+	setupJWT(t)
+	userID, sessionID := uuid.New(), uuid.New()
+
+	first, _ := GenerateRefreshToken(userID, "a@example.com", constants.RoleUser, sessionID)
+	second, _ := GenerateRefreshToken(userID, "a@example.com", constants.RoleUser, sessionID)
+
+	firstClaims, err := ValidateToken(first, true)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	secondClaims, err := ValidateToken(second, true)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if firstClaims.ID == secondClaims.ID {
+		t.Fatal("tokens issued for the same session share a jti")
+	}
+
+	if _, err := ValidateToken(first, false); err == nil {
+		t.Fatal("refresh token accepted as an access token")
+	}
+}
+
+func TestMFATokenIsSingleUseAndNotAnAccessToken(t *testing.T) {
+	setupJWT(t)
+	userID := uuid.New()
+
+	token, err := GenerateMFAToken(userID, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateMFAToken: %v", err)
+	}
+	if _, err := ValidateToken(token, false); err == nil {
+		t.Fatal("MFA token validated as an access token")
+	}
+
+	claims, err := ValidateMFAToken(token)
+	if err != nil || claims.UserID != userID {
+		t.Fatalf("ValidateMFAToken = %+v, %v", claims, err)
+	}
+
+	if err := RevokeMFAToken(claims); err != nil {
+		t.Fatalf("RevokeMFAToken: %v", err)
+	}
+	if _, err := ValidateMFAToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("ValidateMFAToken after revoke = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestPasskeyTokenIsBoundToItsCeremony(t *testing.T) {
+	setupJWT(t)
+	userID := uuid.New()
+
+	token, err := GeneratePasskeyToken(&PasskeyClaims{
+		Ceremony:  PasskeyCeremonyRegistration,
+		Challenge: "challenge",
+		UserID:    userID,
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("GeneratePasskeyToken: %v", err)
+	}
+	if _, err := ValidateToken(token, false); err == nil {
+		t.Fatal("passkey token validated as an access token")
+	}
+	if _, err := ValidatePasskeyToken(token, PasskeyCeremonyLogin); err == nil {
+		t.Fatal("registration token accepted for a sign-in")
+	}
+
+	claims, err := ValidatePasskeyToken(token, PasskeyCeremonyRegistration)
+	if err != nil || claims.UserID != userID || claims.Challenge != "challenge" {
+		t.Fatalf("ValidatePasskeyToken = %+v, %v", claims, err)
+	}
+
+	if err := RevokePasskeyToken(claims); err != nil {
+		t.Fatalf("RevokePasskeyToken: %v", err)
+	}
+	if _, err := ValidatePasskeyToken(token, PasskeyCeremonyRegistration); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("ValidatePasskeyToken after revoke = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestMagicLinkTokenIsNotAnAccessToken(t *testing.T) {
+	setupJWT(t)
+	id := uuid.New()
+
+	token, err := GenerateMagicLinkToken(id, "a@example.com", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GenerateMagicLinkToken: %v", err)
+	}
+	if _, err := ValidateToken(token, false); err == nil {
+		t.Fatal("magic link token validated as an access token")
+	}
+
+	claims, err := ValidateMagicLinkToken(token)
+	if err != nil || claims.ID != id.String() || claims.Email != "a@example.com" {
+		t.Fatalf("ValidateMagicLinkToken = %+v, %v", claims, err)
+	}
+
+	access, _ := GenerateAccessToken(uuid.New(), "a@example.com", constants.RoleUser, uuid.New())
+	if _, err := ValidateMagicLinkToken(access); err == nil {
+		t.Fatal("access token accepted as a magic link")
+	}
+
+	expired, _ := GenerateMagicLinkToken(id, "a@example.com", time.Now().Add(-time.Minute))
+	if _, err := ValidateMagicLinkToken(expired); err == nil {
+		t.Fatal("expired magic link accepted")
+	}
+}
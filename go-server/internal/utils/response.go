@@ -39,6 +39,10 @@ func getValidationMessage(fieldError validator.FieldError) string {
 		return "Value is too short"
 	case "max":
 		return "Value is too long"
+	case "url":
+		return "Please provide a valid URL"
+	case "oneof":
+		return "Value must be one of: " + strings.ReplaceAll(fieldError.Param(), " ", ", ")
 	default:
 		return "Invalid value"
 	}